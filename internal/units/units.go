@@ -0,0 +1,317 @@
+// Package units converts the SI-normalized quantities of the weather model into the
+// units they are displayed in. Temperatures are in °C, speeds in m/s, pressures in hPa,
+// precipitation in mm and distances in meters.
+package units
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Conversion factors from the normalized units
+const (
+	MpsToKph   = 3.6
+	MpsToMph   = 2.23694
+	MpsToKnots = 1.94384
+	HPaToInHg  = 0.0295300
+	HPaToMmHg  = 0.750062
+	HPaToKPa   = 0.1
+	MmToInches = 1 / 25.4
+	MToKm      = 0.001
+	MToMiles   = 1 / 1609.344
+)
+
+// Temperature is a unit temperatures are displayed in
+type Temperature string
+
+const (
+	Celsius    Temperature = "C"
+	Fahrenheit Temperature = "F"
+	Kelvin     Temperature = "K"
+)
+
+// celsiusToKelvin is the offset between both scales
+const celsiusToKelvin = 273.15
+
+// Convert converts a temperature in °C
+func (u Temperature) Convert(celsius float64) float64 {
+	switch u {
+	case Fahrenheit:
+		return celsius*9/5 + 32
+	case Kelvin:
+		return celsius + celsiusToKelvin
+	default:
+		return celsius
+	}
+}
+
+// ConvertDelta converts a temperature difference in °C, like a spread between readings
+func (u Temperature) ConvertDelta(celsius float64) float64 {
+	switch u {
+	case Fahrenheit:
+		return celsius * 9 / 5
+	default:
+		return celsius
+	}
+}
+
+func (u Temperature) Symbol() string {
+	// Kelvins aren't degrees
+	if u == Kelvin {
+		return string(u)
+	}
+	return "°" + string(u)
+}
+
+// Speed is a unit wind speeds are displayed in
+type Speed string
+
+const (
+	KilometersPerHour Speed = "km/h"
+	MilesPerHour      Speed = "mph"
+	MetersPerSecond   Speed = "m/s"
+	Knots             Speed = "knots"
+	Beaufort          Speed = "Beaufort"
+)
+
+// beaufortLimits are the lowest wind speeds of the Beaufort forces from 1 to 12, in m/s
+var beaufortLimits = [...]float64{0.5, 1.6, 3.4, 5.5, 8.0, 10.8, 13.9, 17.2, 20.8, 24.5, 28.5, 32.7}
+
+// Convert converts a speed in m/s, into a force for the Beaufort scale
+func (u Speed) Convert(mps float64) float64 {
+	switch u {
+	case MilesPerHour:
+		return mps * MpsToMph
+	case MetersPerSecond:
+		return mps
+	case Knots:
+		return mps * MpsToKnots
+	case Beaufort:
+		force := 0
+		for force < len(beaufortLimits) && mps >= beaufortLimits[force] {
+			force++
+		}
+		return float64(force)
+	default:
+		return mps * MpsToKph
+	}
+}
+
+func (u Speed) Symbol() string {
+	switch u {
+	case Knots:
+		return "kn"
+	case Beaufort:
+		return "Bft"
+	default:
+		return string(u)
+	}
+}
+
+// Decimals is the number of decimals worth showing, Beaufort forces being whole numbers
+func (u Speed) Decimals() int {
+	if u == Beaufort {
+		return 0
+	}
+	return 1
+}
+
+// Pressure is a unit atmospheric pressures are displayed in
+type Pressure string
+
+const (
+	Hectopascals         Pressure = "hPa"
+	InchesOfMercury      Pressure = "inHg"
+	MillimetersOfMercury Pressure = "mmHg"
+	Kilopascals          Pressure = "kPa"
+)
+
+// Convert converts a pressure in hPa
+func (u Pressure) Convert(hPa float64) float64 {
+	switch u {
+	case InchesOfMercury:
+		return hPa * HPaToInHg
+	case MillimetersOfMercury:
+		return hPa * HPaToMmHg
+	case Kilopascals:
+		return hPa * HPaToKPa
+	default:
+		return hPa
+	}
+}
+
+func (u Pressure) Symbol() string {
+	return string(u)
+}
+
+// Decimals is the number of decimals worth showing for the usual precision of barometers
+func (u Pressure) Decimals() int {
+	switch u {
+	case InchesOfMercury:
+		return 2
+	case Kilopascals:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// Precipitation is a unit precipitation amounts are displayed in
+type Precipitation string
+
+const (
+	Millimeters Precipitation = "mm"
+	Inches      Precipitation = "in"
+)
+
+// Convert converts an amount of precipitation in mm
+func (u Precipitation) Convert(mm float64) float64 {
+	switch u {
+	case Inches:
+		return mm * MmToInches
+	default:
+		return mm
+	}
+}
+
+func (u Precipitation) Symbol() string {
+	return string(u)
+}
+
+// Decimals is the number of decimals worth showing, inches being much larger than millimeters
+func (u Precipitation) Decimals() int {
+	if u == Inches {
+		return 2
+	}
+	return 1
+}
+
+// Distance is a unit distances like the visibility are displayed in
+type Distance string
+
+const (
+	Kilometers Distance = "km"
+	Miles      Distance = "mi"
+	Meters     Distance = "m"
+)
+
+// Convert converts a distance in meters
+func (u Distance) Convert(meters float64) float64 {
+	switch u {
+	case Miles:
+		return meters * MToMiles
+	case Meters:
+		return meters
+	default:
+		return meters * MToKm
+	}
+}
+
+func (u Distance) Symbol() string {
+	return string(u)
+}
+
+// Decimals is the number of decimals worth showing, meters being much smaller than the others
+func (u Distance) Decimals() int {
+	if u == Meters {
+		return 0
+	}
+	return 1
+}
+
+// Set is the unit of every displayed quantity
+type Set struct {
+	Temperature   Temperature
+	Speed         Speed
+	Pressure      Pressure
+	Precipitation Precipitation
+	Distance      Distance
+}
+
+// Names of the presets
+const (
+	PresetMetric   = "metric"
+	PresetImperial = "imperial"
+	// PresetStandard is the raw SI units, as OpenWeatherMap calls them
+	PresetStandard = "standard"
+)
+
+var presets = map[string]Set{
+	PresetMetric:   {Celsius, KilometersPerHour, Hectopascals, Millimeters, Kilometers},
+	PresetImperial: {Fahrenheit, MilesPerHour, InchesOfMercury, Inches, Miles},
+	PresetStandard: {Kelvin, MetersPerSecond, Hectopascals, Millimeters, Meters},
+}
+
+// Preset returns the set of units with the given name
+func Preset(name string) (Set, bool) {
+	set, ok := presets[name]
+	return set, ok
+}
+
+// Spellings accepted for each unit besides the unit itself, in lower case
+var (
+	temperatureAliases = map[string]Temperature{
+		"°c": Celsius, "celsius": Celsius,
+		"°f": Fahrenheit, "fahrenheit": Fahrenheit,
+		"kelvin": Kelvin,
+	}
+	speedAliases = map[string]Speed{
+		"kmh": KilometersPerHour, "kph": KilometersPerHour,
+		"mps": MetersPerSecond, "ms": MetersPerSecond,
+		"kn": Knots, "kt": Knots, "kts": Knots,
+		"bft": Beaufort,
+	}
+	pressureAliases = map[string]Pressure{
+		"mbar": Hectopascals, "mb": Hectopascals,
+	}
+	precipitationAliases = map[string]Precipitation{
+		"inch": Inches, "inches": Inches,
+	}
+	distanceAliases = map[string]Distance{
+		"miles": Miles,
+	}
+)
+
+// ParseTemperature parses a temperature unit such as "C", "°F" or "kelvin"
+func ParseTemperature(s string) (Temperature, error) {
+	return parse(s, []Temperature{Celsius, Fahrenheit, Kelvin}, temperatureAliases)
+}
+
+// ParseSpeed parses a wind speed unit such as "km/h", "knots" or "Beaufort"
+func ParseSpeed(s string) (Speed, error) {
+	return parse(s, []Speed{KilometersPerHour, MilesPerHour, MetersPerSecond, Knots, Beaufort}, speedAliases)
+}
+
+// ParsePressure parses a pressure unit such as "hPa" or "inHg"
+func ParsePressure(s string) (Pressure, error) {
+	return parse(s, []Pressure{Hectopascals, InchesOfMercury, MillimetersOfMercury, Kilopascals}, pressureAliases)
+}
+
+// ParsePrecipitation parses a precipitation unit, "mm" or "in"
+func ParsePrecipitation(s string) (Precipitation, error) {
+	return parse(s, []Precipitation{Millimeters, Inches}, precipitationAliases)
+}
+
+// ParseDistance parses a distance unit such as "km" or "mi"
+func ParseDistance(s string) (Distance, error) {
+	return parse(s, []Distance{Kilometers, Miles, Meters}, distanceAliases)
+}
+
+// parse matches a unit case-insensitively against the valid units and their aliases
+func parse[U ~string](s string, valid []U, aliases map[string]U) (U, error) {
+	name := strings.ToLower(strings.TrimSpace(s))
+	for _, u := range valid {
+		if name == strings.ToLower(string(u)) {
+			return u, nil
+		}
+	}
+	if u, ok := aliases[name]; ok {
+		return u, nil
+	}
+
+	names := make([]string, len(valid))
+	for i, u := range valid {
+		names[i] = string(u)
+	}
+	return "", fmt.Errorf("unknown unit %q, expected one of %s", s, strings.Join(names, ", "))
+}
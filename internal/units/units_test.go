@@ -0,0 +1,138 @@
+package units
+
+import (
+	"math"
+	"testing"
+)
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		name string
+		got  float64
+		want float64
+	}{
+		{"celsius", Celsius.Convert(21.5), 21.5},
+		{"fahrenheit", Fahrenheit.Convert(100), 212},
+		{"fahrenheit below zero", Fahrenheit.Convert(-40), -40},
+		{"kelvin", Kelvin.Convert(0), 273.15},
+		{"fahrenheit delta", Fahrenheit.ConvertDelta(10), 18},
+		{"kelvin delta", Kelvin.ConvertDelta(10), 10},
+		{"km/h", KilometersPerHour.Convert(10), 36},
+		{"mph", MilesPerHour.Convert(10), 22.3694},
+		{"m/s", MetersPerSecond.Convert(10), 10},
+		{"knots", Knots.Convert(10), 19.4384},
+		{"calm Beaufort", Beaufort.Convert(0.2), 0},
+		{"Beaufort lower limit", Beaufort.Convert(3.4), 3},
+		{"Beaufort hurricane", Beaufort.Convert(40), 12},
+		{"hPa", Hectopascals.Convert(1013.25), 1013.25},
+		{"inHg", InchesOfMercury.Convert(1013.25), 29.9213},
+		{"mmHg", MillimetersOfMercury.Convert(1013.25), 760.0},
+		{"kPa", Kilopascals.Convert(1013.25), 101.325},
+		{"mm", Millimeters.Convert(2.5), 2.5},
+		{"inches", Inches.Convert(25.4), 1},
+		{"km", Kilometers.Convert(10000), 10},
+		{"miles", Miles.Convert(1609.344), 1},
+		{"meters", Meters.Convert(850), 850},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if math.Abs(tt.got-tt.want) > 0.001 {
+				t.Errorf("got %v, want %v", tt.got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSymbol(t *testing.T) {
+	tests := []struct {
+		got, want string
+	}{
+		{Celsius.Symbol(), "°C"},
+		{Fahrenheit.Symbol(), "°F"},
+		{Kelvin.Symbol(), "K"},
+		{Knots.Symbol(), "kn"},
+		{Beaufort.Symbol(), "Bft"},
+		{KilometersPerHour.Symbol(), "km/h"},
+		{InchesOfMercury.Symbol(), "inHg"},
+		{Miles.Symbol(), "mi"},
+	}
+
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("got symbol %q, want %q", tt.got, tt.want)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		parse   func(string) (string, error)
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{"temperature", asString(ParseTemperature), "F", "F", false},
+		{"temperature lower case", asString(ParseTemperature), "c", "C", false},
+		{"temperature with degree sign", asString(ParseTemperature), "°F", "F", false},
+		{"temperature name", asString(ParseTemperature), "Kelvin", "K", false},
+		{"unknown temperature", asString(ParseTemperature), "rankine", "", true},
+		{"speed", asString(ParseSpeed), "km/h", "km/h", false},
+		{"speed alias", asString(ParseSpeed), "kmh", "km/h", false},
+		{"speed with spaces", asString(ParseSpeed), "  MPH ", "mph", false},
+		{"Beaufort alias", asString(ParseSpeed), "bft", "Beaufort", false},
+		{"knots alias", asString(ParseSpeed), "kt", "knots", false},
+		{"unknown speed", asString(ParseSpeed), "furlongs per fortnight", "", true},
+		{"pressure", asString(ParsePressure), "inhg", "inHg", false},
+		{"pressure alias", asString(ParsePressure), "mbar", "hPa", false},
+		{"unknown pressure", asString(ParsePressure), "atm", "", true},
+		{"precipitation", asString(ParsePrecipitation), "MM", "mm", false},
+		{"precipitation alias", asString(ParsePrecipitation), "inches", "in", false},
+		{"distance", asString(ParseDistance), "km", "km", false},
+		{"distance alias", asString(ParseDistance), "miles", "mi", false},
+		{"empty", asString(ParseDistance), "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.parse(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// asString adapts a unit parser for table tests mixing units of different types
+func asString[U ~string](parse func(string) (U, error)) func(string) (string, error) {
+	return func(s string) (string, error) {
+		u, err := parse(s)
+		return string(u), err
+	}
+}
+
+func TestPreset(t *testing.T) {
+	tests := []struct {
+		name string
+		want Set
+		ok   bool
+	}{
+		{PresetMetric, Set{Celsius, KilometersPerHour, Hectopascals, Millimeters, Kilometers}, true},
+		{PresetImperial, Set{Fahrenheit, MilesPerHour, InchesOfMercury, Inches, Miles}, true},
+		{PresetStandard, Set{Kelvin, MetersPerSecond, Hectopascals, Millimeters, Meters}, true},
+		{"nautical", Set{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := Preset(tt.name)
+			if ok != tt.ok || got != tt.want {
+				t.Errorf("got %+v, %v, want %+v, %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package weather
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// cacheEntry wraps a cached value with the time it was stored
+type cacheEntry[T any] struct {
+	StoredAt time.Time `json:"stored_at"`
+	Value    T         `json:"value"`
+}
+
+// cacheFilePath returns the file holding the cached value of the given kind and key.
+// Keys are hashed so that arbitrary queries can be used safely as file names.
+func cacheFilePath(kind, key string) string {
+	cacheDir := GetCacheDir()
+	if cacheDir == "" {
+		return ""
+	}
+
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(cacheDir, fmt.Sprintf("%s-%s.json", kind, hex.EncodeToString(sum[:8])))
+}
+
+// readCache loads a cached value, reporting whether one was found along with its storage time
+func readCache[T any](kind, key string) (value T, storedAt time.Time, ok bool) {
+	path := cacheFilePath(kind, key)
+	if path == "" {
+		return
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+
+	var entry cacheEntry[T]
+	if err = json.Unmarshal(data, &entry); err != nil {
+		return
+	}
+
+	return entry.Value, entry.StoredAt, true
+}
+
+// storeCache stores a value in the cache on a best-effort basis: the cache only saves
+// requests, so failing to write it is logged rather than returned
+func storeCache[T any](ctx context.Context, kind, key string, value T) {
+	if err := writeCache(kind, key, value); err != nil {
+		Logger.DebugContext(ctx, "cache write failed", "kind", kind, "error", err)
+	}
+}
+
+// writeCache stores a value in the cache, replacing any previous value for the same key
+func writeCache[T any](kind, key string, value T) error {
+	path := cacheFilePath(kind, key)
+	if path == "" {
+		return fmt.Errorf("no cache directory available")
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	data, err := json.Marshal(cacheEntry[T]{StoredAt: time.Now(), Value: value})
+	if err != nil {
+		return fmt.Errorf("failed to encode cache entry: %w", err)
+	}
+
+	// Write to a temporary file first so concurrent invocations never read a partial entry
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to create cache file: %w", err)
+	}
+	if _, err = tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write cache file: %w", err)
+	}
+	if err = tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write cache file: %w", err)
+	}
+
+	if err = os.Rename(tmp.Name(), path); err != nil {
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write cache file: %w", err)
+	}
+
+	return nil
+}
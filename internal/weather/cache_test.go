@@ -0,0 +1,62 @@
+package weather
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// captureLog sends the debug trace to the returned buffer for the duration of the test
+func captureLog(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	previous := Logger
+	Logger = slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	t.Cleanup(func() { Logger = previous })
+	return &buf
+}
+
+func TestCacheRoundTrip(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	if _, _, ok := readCache[[]GeoResult]("geocode", "paris"); ok {
+		t.Fatal("found an entry in an empty cache")
+	}
+
+	stored := []GeoResult{{Name: "Paris", Latitude: 48.8566, Longitude: 2.3522, CountryCode: "FR"}}
+	if err := writeCache("geocode", "paris", stored); err != nil {
+		t.Fatalf("writing failed: %v", err)
+	}
+
+	got, storedAt, ok := readCache[[]GeoResult]("geocode", "paris")
+	if !ok || len(got) != 1 || got[0] != stored[0] {
+		t.Errorf("got %+v, %v, want %+v", got, ok, stored)
+	}
+	if time.Since(storedAt) > time.Minute {
+		t.Errorf("got storage time %v, want now", storedAt)
+	}
+	if _, _, ok := readCache[[]GeoResult]("weather", "paris"); ok {
+		t.Error("found an entry of another kind under the same key")
+	}
+}
+
+func TestStoreCacheFailure(t *testing.T) {
+	// A file where the cache directory should be makes every write fail
+	blocker := filepath.Join(t.TempDir(), "cache")
+	if err := os.WriteFile(blocker, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("XDG_CACHE_HOME", blocker)
+	log := captureLog(t)
+
+	storeCache(context.Background(), "geocode", "paris", []GeoResult{{Name: "Paris"}})
+
+	if !strings.Contains(log.String(), "cache write failed") || !strings.Contains(log.String(), "kind=geocode") {
+		t.Errorf("got log %q, want the failed write traced", log.String())
+	}
+}
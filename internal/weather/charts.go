@@ -0,0 +1,261 @@
+package weather
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/ashish0kumar/stormy/internal/units"
+	"github.com/fatih/color"
+)
+
+var (
+	// sparkLevels are the characters of sparklines and bar charts, from lowest to highest
+	sparkLevels = []rune("▁▂▃▄▅▆▇█")
+	// asciiSparkLevels replace them when colors are off, as terminals without colors may
+	// lack the glyphs as well
+	asciiSparkLevels = []rune("_.-:=+*#")
+)
+
+const (
+	// sparklineMaxLength is the longest series shown as a sparkline, longer temperature
+	// series are plotted as a line graph
+	sparklineMaxLength = 48
+	// chartMaxWidth is the most characters a chart spans, longer series are resampled
+	chartMaxWidth = 48
+	// graphHeight is the number of lines of line graphs
+	graphHeight = 4
+)
+
+// brailleDots are the bits of the dots of a braille character, by row and column.
+// Characters from U+2800 on have a dot raised for each set bit.
+var brailleDots = [4][2]rune{{0x01, 0x08}, {0x02, 0x10}, {0x04, 0x20}, {0x40, 0x80}}
+
+// sparkline renders each value as a level character, scaled from low to high
+func sparkline(values []float64, low, high float64, levels []rune) []string {
+	cells := make([]string, len(values))
+	top := len(levels) - 1
+	for i, value := range values {
+		level := 0
+		if high > low {
+			level = int(math.Round((value - low) / (high - low) * float64(top)))
+		}
+		cells[i] = string(levels[min(max(level, 0), top)])
+	}
+	return cells
+}
+
+// brailleGraph plots the values as a line through a grid of braille characters, each made
+// of 2 by 4 dots, and returns the characters of its rows from the top. When there are more
+// values than dots across, each dot column spans the range of the values it covers.
+func brailleGraph(values []float64, width, height int) [][]string {
+	low, high := slices.Min(values), slices.Max(values)
+	dots := make([][]rune, height)
+	for row := range dots {
+		dots[row] = make([]rune, width)
+	}
+
+	points, levels := 2*width, 4*height
+	levelOf := func(value float64) int {
+		if high <= low {
+			return levels - 1
+		}
+		return int(math.Round((high - value) / (high - low) * float64(levels-1)))
+	}
+
+	// span returns the highest and lowest levels of the dot column, from the top
+	span := func(x int) (top, bottom int) {
+		if len(values) <= points {
+			level := levelOf(sampleAt(values, x, points))
+			return level, level
+		}
+		covered := values[x*len(values)/points : (x+1)*len(values)/points]
+		return levelOf(slices.Max(covered)), levelOf(slices.Min(covered))
+	}
+
+	for x := range points {
+		top, bottom := span(x)
+		// Join the column to the previous one so that steep changes don't leave gaps
+		if x > 0 {
+			previousTop, previousBottom := span(x - 1)
+			top = min(top, previousBottom+1)
+			bottom = max(bottom, previousTop-1)
+		}
+		for level := top; level <= bottom; level++ {
+			dots[level/4][x/2] |= brailleDots[level%4][x%2]
+		}
+	}
+
+	rows := make([][]string, height)
+	for row, bits := range dots {
+		rows[row] = make([]string, width)
+		for column, bit := range bits {
+			rows[row][column] = string(0x2800 + bit)
+		}
+	}
+	return rows
+}
+
+// asciiGraph plots the values as a line of slashes and dashes, one point per character,
+// and returns the characters of its rows from the top. Values are reduced to their median
+// when there are more than characters across.
+func asciiGraph(values []float64, width, height int) [][]string {
+	values = resample(values, width, median)
+	low, high := slices.Min(values), slices.Max(values)
+	levelOf := func(x int) int {
+		if high <= low {
+			return height - 1
+		}
+		return int(math.Round((high - sampleAt(values, x, width)) / (high - low) * float64(height-1)))
+	}
+
+	rows := make([][]string, height)
+	for row := range rows {
+		rows[row] = slices.Repeat([]string{" "}, width)
+	}
+	for x := range width {
+		y, line := levelOf(x), "-"
+		if x+1 < width {
+			switch next := levelOf(x + 1); {
+			case next < y:
+				line = "/"
+			case next > y:
+				line = "\\"
+			}
+		}
+		rows[y][x] = line
+	}
+	return rows
+}
+
+// sampleAt returns the value at the x-th of the given number of points spread evenly over
+// the values, interpolated between the nearest ones
+func sampleAt(values []float64, x, points int) float64 {
+	if points <= 1 || len(values) == 1 {
+		return values[0]
+	}
+
+	position := float64(x) * float64(len(values)-1) / float64(points-1)
+	i := min(int(position), len(values)-2)
+	return values[i] + (values[i+1]-values[i])*(position-float64(i))
+}
+
+// resample reduces the values to the given number of buckets, combining those of each
+func resample(values []float64, buckets int, combine func([]float64) float64) []float64 {
+	if len(values) <= buckets {
+		return values
+	}
+
+	resampled := make([]float64, buckets)
+	for i := range resampled {
+		resampled[i] = combine(values[i*len(values)/buckets : (i+1)*len(values)/buckets])
+	}
+	return resampled
+}
+
+// colorChart colors each character of a chart row with the color of the condition of the
+// part of the series it shows, conditions being spread evenly over the row
+func colorChart(cells []string, conditions []string, useColors bool) string {
+	if !useColors {
+		return strings.Join(cells, "")
+	}
+
+	var b strings.Builder
+	for i, cell := range cells {
+		b.WriteString(getColoredWeatherText(conditions[i*len(conditions)/len(cells)], cell))
+	}
+	return b.String()
+}
+
+// forecastCharts charts the temperature and precipitation of forecast steps. The temperature
+// is a sparkline, or a line graph over longer ranges, and the precipitation a bar chart.
+// Charts are drawn with ASCII characters when colors are off.
+func forecastCharts(steps []ForecastStep, unitSet units.Set, useColors bool) []string {
+	if len(steps) == 0 {
+		return nil
+	}
+
+	temperatures := make([]float64, len(steps))
+	precipitations := make([]float64, len(steps))
+	conditions := make([]string, len(steps))
+	for i, step := range steps {
+		temperatures[i] = unitSet.Temperature.Convert(step.Temp)
+		precipitations[i] = unitSet.Precipitation.Convert(step.Precipitation)
+		conditions[i] = step.Condition.Main
+	}
+
+	label := func(text string) string {
+		text = fmt.Sprintf("%-7s", text)
+		if useColors {
+			return color.BlueString(text)
+		}
+		return text
+	}
+	levels := asciiSparkLevels
+	if useColors {
+		levels = sparkLevels
+	}
+
+	lines := make([]string, 0, graphHeight+1)
+	width := min(len(steps), chartMaxWidth)
+	if len(steps) <= sparklineMaxLength {
+		lines = append(lines, label("Temp")+temperatureSparkline(steps, unitSet, useColors))
+	} else {
+		// Braille characters hold two points each
+		width = min((len(steps)+1)/2, chartMaxWidth)
+		lowLabel := fmt.Sprintf("%.0f %s", slices.Min(temperatures), unitSet.Temperature.Symbol())
+		highLabel := fmt.Sprintf("%.0f %s", slices.Max(temperatures), unitSet.Temperature.Symbol())
+		rows := asciiGraph(temperatures, width, graphHeight)
+		if useColors {
+			rows = brailleGraph(temperatures, width, graphHeight)
+		}
+		for i, row := range rows {
+			text, value := "", ""
+			switch i {
+			case 0:
+				text, value = "Temp", highLabel
+			case len(rows) - 1:
+				value = lowLabel
+			}
+			if useColors && value != "" {
+				value = color.RedString(value)
+			}
+			lines = append(lines, label(text)+colorChart(row, conditions, useColors)+"  "+value)
+		}
+	}
+
+	bars := resample(precipitations, width, slices.Max[[]float64])
+	wettest := slices.Max(bars)
+	total := fmt.Sprintf("max %.*f %s", unitSet.Precipitation.Decimals(), wettest, unitSet.Precipitation.Symbol())
+	if useColors {
+		total = color.BlueString(total)
+	}
+	lines = append(lines, label("Precip")+colorChart(sparkline(bars, 0, wettest, levels), conditions, useColors)+"  "+total)
+
+	return lines
+}
+
+// temperatureSparkline charts the temperature of forecast steps as a sparkline followed by
+// the lowest and highest temperatures
+func temperatureSparkline(steps []ForecastStep, unitSet units.Set, useColors bool) string {
+	temperatures := make([]float64, len(steps))
+	conditions := make([]string, len(steps))
+	tempMin, tempMax := steps[0].Temp, steps[0].Temp
+	for i, step := range steps {
+		temperatures[i] = unitSet.Temperature.Convert(step.Temp)
+		conditions[i] = step.Condition.Main
+		tempMin, tempMax = min(tempMin, step.Temp), max(tempMax, step.Temp)
+	}
+
+	levels := asciiSparkLevels
+	if useColors {
+		levels = sparkLevels
+	}
+	line := sparkline(temperatures, slices.Min(temperatures), slices.Max(temperatures), levels)
+	tempRange := formatTempRange(tempMin, tempMax, unitSet.Temperature)
+	if useColors {
+		tempRange = color.RedString(tempRange)
+	}
+	return colorChart(line, conditions, useColors) + "  " + tempRange
+}
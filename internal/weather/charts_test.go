@@ -0,0 +1,99 @@
+package weather
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestSparkline(t *testing.T) {
+	tests := []struct {
+		name      string
+		values    []float64
+		low, high float64
+		levels    []rune
+		want      string
+	}{
+		{"scaled", []float64{0, 5, 10}, 0, 10, sparkLevels, "▁▅█"},
+		{"clamped", []float64{-5, 15}, 0, 10, sparkLevels, "▁█"},
+		{"flat", []float64{3, 3, 3}, 3, 3, sparkLevels, "▁▁▁"},
+		{"ascii", []float64{0, 2, 4, 7}, 0, 7, asciiSparkLevels, "_-=#"},
+		{"empty", nil, 0, 10, sparkLevels, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := strings.Join(sparkline(tt.values, tt.low, tt.high, tt.levels), ""); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBrailleGraph(t *testing.T) {
+	tests := []struct {
+		name          string
+		values        []float64
+		width, height int
+		want          []string
+	}{
+		// The rise fills the right column from the top down to the dot above the left one
+		{"rising", []float64{0, 1}, 1, 1, []string{"⡸"}},
+		{"flat", []float64{5, 5}, 1, 1, []string{"⣀"}},
+		{"falling over two rows", []float64{1, 0}, 1, 2, []string{"⢱", "⢸"}},
+		{"more values than dots", []float64{0, 4, 0, 4}, 1, 1, []string{"⣿"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows := brailleGraph(tt.values, tt.width, tt.height)
+			got := make([]string, len(rows))
+			for i, row := range rows {
+				got[i] = strings.Join(row, "")
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBrailleGraphSize(t *testing.T) {
+	values := make([]float64, 100)
+	for i := range values {
+		values[i] = float64(i % 7)
+	}
+
+	rows := brailleGraph(values, chartMaxWidth, graphHeight)
+	if len(rows) != graphHeight {
+		t.Fatalf("got %d rows, want %d", len(rows), graphHeight)
+	}
+	for i, row := range rows {
+		if len(row) != chartMaxWidth {
+			t.Errorf("row %d has %d characters, want %d", i, len(row), chartMaxWidth)
+		}
+	}
+}
+
+func TestResample(t *testing.T) {
+	tests := []struct {
+		name    string
+		values  []float64
+		buckets int
+		combine func([]float64) float64
+		want    []float64
+	}{
+		{"fewer values", []float64{1, 2}, 3, median, []float64{1, 2}},
+		{"even buckets", []float64{1, 2, 3, 4, 5, 6}, 3, median, []float64{1.5, 3.5, 5.5}},
+		{"uneven buckets", []float64{1, 2, 3, 4, 5, 6, 7}, 3, slices.Max[[]float64], []float64{2, 4, 7}},
+		{"single bucket", []float64{4, 1, 9}, 1, median, []float64{4}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := resample(tt.values, tt.buckets, tt.combine); !slices.Equal(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -3,52 +3,329 @@ package weather
 import (
 	"flag"
 	"fmt"
+	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
 	"runtime"
 	"slices"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/BurntSushi/toml"
+	"github.com/ashish0kumar/stormy/internal/units"
 )
 
 // Config holds the application configuration
 type Config struct {
-	Provider     string `toml:"provider"`
-	ApiKey       string `toml:"api_key"`
-	City         string `toml:"city"`
-	Units        string `toml:"units"`
-	ShowCityName bool   `toml:"showcityname"`
-	UseColors    bool   `toml:"use_colors"`
-	LiveMode     bool   `toml:"live_mode"`
-	Compact      bool   `toml:"compact"`
+	Provider       string   `toml:"provider"`
+	Providers      []string `toml:"providers"`
+	ApiKey         string   `toml:"api_key"`
+	City           string   `toml:"city"`
+	Latitude       *float64 `toml:"latitude,omitempty"`
+	Longitude      *float64 `toml:"longitude,omitempty"`
+	Label          string   `toml:"label,omitempty"`
+	ResolvedCity   string   `toml:"resolved_city,omitempty"` // the city the coordinates were picked for
+	Units          Units    `toml:"units"`
+	ShowCityName   bool     `toml:"showcityname"`
+	UseColors      bool     `toml:"use_colors"`
+	LiveMode       bool     `toml:"live_mode"`
+	Compact        bool     `toml:"compact"`
+	ShowTrend      bool     `toml:"show_trend"`
+	Ensemble       bool     `toml:"ensemble"`
+	CacheMaxAge    Duration `toml:"cache_max_age"`
+	Timeout        Duration `toml:"timeout"`
+	ConnectTimeout Duration `toml:"connect_timeout"`
+	Retries        int      `toml:"retries"`
+
+	Proxy              string `toml:"proxy"`
+	CAFile             string `toml:"ca_file"`
+	InsecureSkipVerify bool   `toml:"insecure_skip_verify"`
+
+	// Endpoints overrides the URLs of providers, keyed by provider name
+	Endpoints map[string]Endpoint `toml:"endpoints,omitempty"`
+
+	// RefreshLocation bypasses the geocoding cache for this run
+	RefreshLocation bool `toml:"-"`
+	// Offline shows the last known weather without any network request
+	Offline bool `toml:"-"`
+	// HTTPClient is used for every request, it's built by ValidateConfig unless injected
+	HTTPClient *http.Client `toml:"-"`
+}
+
+// Duration is a time.Duration written as a string such as "10m" in the config file
+type Duration struct {
+	time.Duration
+}
+
+func (d *Duration) UnmarshalText(text []byte) (err error) {
+	d.Duration, err = time.ParseDuration(string(text))
+	return err
+}
+
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// Endpoint holds the URLs a provider is reached at, to point stormy at a self-hosted
+// instance, a mirror or a proxy. Empty fields keep the provider's default.
+type Endpoint struct {
+	BaseURL      string `toml:"base_url,omitempty"`
+	GeocodingURL string `toml:"geocoding_url,omitempty"`
+}
+
+// Units selects the units readings are displayed in: a preset, with the unit of some
+// quantities optionally overridden. The config file has it either as a preset name,
+// units = "metric", or as a table of overrides with an optional preset.
+type Units struct {
+	Preset        string `toml:"preset"`
+	Temperature   string `toml:"temperature,omitempty"`
+	Wind          string `toml:"wind,omitempty"`
+	Pressure      string `toml:"pressure,omitempty"`
+	Precipitation string `toml:"precipitation,omitempty"`
+	Distance      string `toml:"distance,omitempty"`
+}
+
+func (u *Units) UnmarshalTOML(value any) error {
+	switch v := value.(type) {
+	case string:
+		*u = Units{Preset: v}
+	case map[string]any:
+		fields := map[string]*string{
+			"preset":        &u.Preset,
+			"temperature":   &u.Temperature,
+			"wind":          &u.Wind,
+			"pressure":      &u.Pressure,
+			"precipitation": &u.Precipitation,
+			"distance":      &u.Distance,
+		}
+		for key, field := range v {
+			target, ok := fields[key]
+			if !ok {
+				return fmt.Errorf("unknown key %q in units", key)
+			}
+			if *target, ok = field.(string); !ok {
+				return fmt.Errorf("units.%s must be a string", key)
+			}
+		}
+	default:
+		return fmt.Errorf("units must be a preset name or a table, got %T", value)
+	}
+	return nil
+}
+
+// MarshalTOML writes the units as a preset name unless some are overridden
+func (u Units) MarshalTOML() ([]byte, error) {
+	if u.overrides() == (Units{}) {
+		return []byte(strconv.Quote(u.Preset)), nil
+	}
+
+	fields := []string{"preset = " + strconv.Quote(u.Preset)}
+	for _, field := range [...]struct{ key, value string }{
+		{"temperature", u.Temperature},
+		{"wind", u.Wind},
+		{"pressure", u.Pressure},
+		{"precipitation", u.Precipitation},
+		{"distance", u.Distance},
+	} {
+		if field.value != "" {
+			fields = append(fields, field.key+" = "+strconv.Quote(field.value))
+		}
+	}
+	return []byte("{ " + strings.Join(fields, ", ") + " }"), nil
+}
+
+// overrides returns the units without their preset
+func (u Units) overrides() Units {
+	u.Preset = ""
+	return u
+}
+
+// Endpoint returns the URLs to use for a provider: the STORMY_<PROVIDER>_BASE_URL and
+// STORMY_<PROVIDER>_GEOCODING_URL environment variables take precedence over the config
+// file, which takes precedence over the given defaults
+func (c Config) Endpoint(provider string, defaults Endpoint) Endpoint {
+	endpoint := defaults
+	if configured, ok := c.Endpoints[provider]; ok {
+		if configured.BaseURL != "" {
+			endpoint.BaseURL = configured.BaseURL
+		}
+		if configured.GeocodingURL != "" {
+			endpoint.GeocodingURL = configured.GeocodingURL
+		}
+	}
+
+	envPrefix := "STORMY_" + strings.ToUpper(provider)
+	if baseURL := os.Getenv(envPrefix + "_BASE_URL"); baseURL != "" {
+		endpoint.BaseURL = baseURL
+	}
+	if geocodingURL := os.Getenv(envPrefix + "_GEOCODING_URL"); geocodingURL != "" {
+		endpoint.GeocodingURL = geocodingURL
+	}
+
+	endpoint.BaseURL = strings.TrimRight(endpoint.BaseURL, "/")
+	endpoint.GeocodingURL = strings.TrimRight(endpoint.GeocodingURL, "/")
+	return endpoint
 }
 
 // Flags holds command line flags
 type Flags struct {
-	City, Units            string
-	Compact, Help, Version bool
+	City, Units, Label, LogFormat    string
+	TemperatureUnit, WindUnit        string
+	PressureUnit, PrecipitationUnit  string
+	DistanceUnit                     string
+	Latitude, Longitude              *float64
+	Compact, Ensemble, Help, Version bool
+	Trend, RefreshLocation, Offline  bool
+	Verbose, Debug                   bool
+	// Hourly is the number of hours to forecast, 0 for the current weather
+	Hourly int
+	// Days is the number of days to forecast, 0 for the current weather
+	Days int
+}
+
+// hoursFlag is a number of hours that can be left out, the flag alone asking for the
+// default number of hours
+type hoursFlag struct {
+	hours *int
+}
+
+func (f hoursFlag) String() string {
+	if f.hours == nil || *f.hours == 0 {
+		return ""
+	}
+	return strconv.Itoa(*f.hours)
+}
+
+func (f hoursFlag) Set(s string) error {
+	hours, err := strconv.Atoi(s)
+	if err != nil {
+		// Set with "true" or "false" when given without a number
+		enabled, boolErr := strconv.ParseBool(s)
+		if boolErr != nil {
+			return fmt.Errorf("expected a number of hours")
+		}
+		hours = 0
+		if enabled {
+			hours = DefaultForecastHours
+		}
+	} else if hours < 1 || hours > MaxForecastHours {
+		return fmt.Errorf("must be between 1 and %d", MaxForecastHours)
+	}
+
+	*f.hours = hours
+	return nil
+}
+
+func (hoursFlag) IsBoolFlag() bool {
+	return true
 }
 
 const (
-	UnitMetric   = "metric"
-	UnitImperial = "imperial"
+	UnitMetric   = units.PresetMetric
+	UnitImperial = units.PresetImperial
+	UnitStandard = units.PresetStandard
 )
 
-var validUnits = [...]string{UnitMetric, UnitImperial}
+var validUnits = [...]string{UnitMetric, UnitImperial, UnitStandard}
+
+const (
+	defaultTimeout        = 10 * time.Second
+	defaultConnectTimeout = 5 * time.Second
+)
 
 // DefaultConfig returns a new Config with default values
 func DefaultConfig() Config {
 	return Config{
-		Provider:     ProviderOpenMeteo,
-		ApiKey:       "",
-		City:         "",
-		Units:        UnitMetric,
-		ShowCityName: false,
-		UseColors:    true,
-		LiveMode:     false,
-		Compact:      false,
+		Provider:       ProviderOpenMeteo,
+		ApiKey:         "",
+		City:           "",
+		Units:          Units{Preset: UnitMetric},
+		ShowCityName:   false,
+		UseColors:      true,
+		LiveMode:       false,
+		Compact:        false,
+		ShowTrend:      false,
+		Ensemble:       false,
+		CacheMaxAge:    Duration{10 * time.Minute},
+		Timeout:        Duration{defaultTimeout},
+		ConnectTimeout: Duration{defaultConnectTimeout},
+		Retries:        2,
+	}
+}
+
+// ProviderChain returns the providers to query in order of preference.
+// The providers list takes precedence over the single provider when set.
+func (c Config) ProviderChain() []string {
+	if len(c.Providers) > 0 {
+		return c.Providers
+	}
+	return []string{c.Provider}
+}
+
+// Coordinates returns the configured coordinates, ok is false when the location
+// must be resolved from the city name instead
+func (c Config) Coordinates() (latitude, longitude float64, ok bool) {
+	if c.Latitude == nil || c.Longitude == nil {
+		return 0, 0, false
 	}
+	return *c.Latitude, *c.Longitude, true
+}
+
+// DisplayUnits returns the units readings are displayed in: the configured preset, metric
+// by default, with the overridden units replaced
+func (c Config) DisplayUnits() units.Set {
+	set, ok := units.Preset(c.Units.Preset)
+	if !ok {
+		set, _ = units.Preset(UnitMetric)
+	}
+
+	if temperature, err := units.ParseTemperature(c.Units.Temperature); err == nil {
+		set.Temperature = temperature
+	}
+	if speed, err := units.ParseSpeed(c.Units.Wind); err == nil {
+		set.Speed = speed
+	}
+	if pressure, err := units.ParsePressure(c.Units.Pressure); err == nil {
+		set.Pressure = pressure
+	}
+	if precipitation, err := units.ParsePrecipitation(c.Units.Precipitation); err == nil {
+		set.Precipitation = precipitation
+	}
+	if distance, err := units.ParseDistance(c.Units.Distance); err == nil {
+		set.Distance = distance
+	}
+
+	return set
+}
+
+// ParseCoordinates parses a "latitude,longitude" pair such as "51.5,-0.12"
+func ParseCoordinates(s string) (latitude, longitude float64, err error) {
+	latPart, lonPart, found := strings.Cut(s, ",")
+	if !found {
+		return 0, 0, fmt.Errorf("expected \"latitude,longitude\", got %q", s)
+	}
+
+	if latitude, err = strconv.ParseFloat(strings.TrimSpace(latPart), 64); err != nil {
+		return 0, 0, fmt.Errorf("invalid latitude %q", latPart)
+	}
+	if longitude, err = strconv.ParseFloat(strings.TrimSpace(lonPart), 64); err != nil {
+		return 0, 0, fmt.Errorf("invalid longitude %q", lonPart)
+	}
+
+	return latitude, longitude, validateCoordinates(latitude, longitude)
+}
+
+// validateCoordinates checks that the coordinates are within range
+func validateCoordinates(latitude, longitude float64) error {
+	if latitude < -90 || latitude > 90 {
+		return fmt.Errorf("latitude %g is out of range [-90, 90]", latitude)
+	}
+	if longitude < -180 || longitude > 180 {
+		return fmt.Errorf("longitude %g is out of range [-180, 180]", longitude)
+	}
+	return nil
 }
 
 // GetConfigPath returns the path to the config file following XDG Base Directory Specification
@@ -87,33 +364,196 @@ func GetConfigPath() string {
 	return filepath.Join(configDir, "stormy.toml")
 }
 
-// ValidateConfig checks if the config is valid
-func ValidateConfig(config *Config) {
+// GetCacheDir returns the directory for cached data following XDG Base Directory Specification
+func GetCacheDir() string {
+	if runtime.GOOS == "windows" {
+		// Windows: Use LocalAppData directory
+		dir, err := os.UserCacheDir()
+		if err != nil {
+			_, _ = fmt.Fprintln(os.Stderr, "Failed to get cache directory:", err)
+			return ""
+		}
+		return filepath.Join(dir, "stormy")
+	}
+
+	// Linux/macOS: Follow XDG Base Directory Specification
+	xdgCacheHome := os.Getenv("XDG_CACHE_HOME")
+	if xdgCacheHome != "" {
+		return filepath.Join(xdgCacheHome, "stormy")
+	}
+
+	// Fall back to ~/.cache/stormy
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, "Failed to get home directory:", err)
+		return ""
+	}
+	return filepath.Join(homeDir, ".cache", "stormy")
+}
+
+// validateUnit normalizes the spelling of an overridden unit, dropping the override when invalid
+func validateUnit[U ~string](unit *string, quantity string, parse func(string) (U, error)) {
+	if *unit == "" {
+		return
+	}
+
+	parsed, err := parse(*unit)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Warning: Invalid %s unit in config: %v. Using the preset's.\n", quantity, err)
+		*unit = ""
+		return
+	}
+	*unit = string(parsed)
+}
+
+// validatePreset falls back to the metric preset when the preset is unknown
+func validatePreset(preset *string) {
+	if !slices.Contains(validUnits[:], *preset) {
+		_, _ = fmt.Fprintf(os.Stderr, "Warning: Invalid units in config. Using '%s' as default.\n", UnitMetric)
+		*preset = UnitMetric
+	}
+}
+
+// unitField is a field of the units along with its validation
+type unitField struct {
+	value    *string
+	validate func(*string)
+}
+
+// fields returns the preset followed by the overridden units, in the order of the unit flags
+func (u *Units) fields() [6]unitField {
+	return [...]unitField{
+		{&u.Preset, validatePreset},
+		{&u.Temperature, func(unit *string) { validateUnit(unit, "temperature", units.ParseTemperature) }},
+		{&u.Wind, func(unit *string) { validateUnit(unit, "wind", units.ParseSpeed) }},
+		{&u.Pressure, func(unit *string) { validateUnit(unit, "pressure", units.ParsePressure) }},
+		{&u.Precipitation, func(unit *string) { validateUnit(unit, "precipitation", units.ParsePrecipitation) }},
+		{&u.Distance, func(unit *string) { validateUnit(unit, "distance", units.ParseDistance) }},
+	}
+}
+
+// ValidateConfig checks if the config is valid, fixing what it can with a warning. It
+// returns an error for network settings the HTTP client can't be built with.
+func ValidateConfig(config *Config) error {
 	const defaultProvider = ProviderOpenMeteo
-	const defaultUnit = UnitMetric
 
 	// Validate provider
-	if !slices.Contains(providers[:], config.Provider) {
+	provider, ok := LookupProvider(config.Provider)
+	if !ok {
 		_, _ = fmt.Fprintf(
 			os.Stderr, "Warning: Invalid provider in config. Using '%s' as default.\n", defaultProvider,
 		)
 		config.Provider = defaultProvider
+		provider, _ = LookupProvider(defaultProvider)
+	}
+
+	// Validate the fallback chain, dropping unknown providers
+	if len(config.Providers) > 0 {
+		chain := make([]string, 0, len(config.Providers))
+		for _, name := range config.Providers {
+			if _, ok := LookupProvider(name); !ok {
+				_, _ = fmt.Fprintf(os.Stderr, "Warning: Ignoring invalid provider '%s' in providers.\n", name)
+				continue
+			}
+			chain = append(chain, name)
+		}
+		config.Providers = chain
+	}
+
+	// Coordinates picked among the matches of a city don't locate another one
+	if config.ResolvedCity != "" && normalizeQuery(config.ResolvedCity) != normalizeQuery(config.City) {
+		config.Latitude, config.Longitude, config.Label, config.ResolvedCity = nil, nil, "", ""
+	}
+
+	// Validate coordinates, falling back to the city when they're incomplete or out of range
+	if (config.Latitude == nil) != (config.Longitude == nil) {
+		_, _ = fmt.Fprintln(os.Stderr, "Warning: Both 'latitude' and 'longitude' are required. Using the city instead.")
+		config.Latitude, config.Longitude = nil, nil
+	} else if latitude, longitude, ok := config.Coordinates(); ok {
+		if err := validateCoordinates(latitude, longitude); err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "Warning: Invalid coordinates in config: %v. Using the city instead.\n", err)
+			config.Latitude, config.Longitude = nil, nil
+		}
+	}
+
+	// Validate timeouts, a hung upstream must never freeze the CLI
+	if config.Timeout.Duration <= 0 {
+		_, _ = fmt.Fprintf(os.Stderr, "Warning: Invalid timeout in config. Using '%s' as default.\n", defaultTimeout)
+		config.Timeout = Duration{defaultTimeout}
+	}
+	if config.ConnectTimeout.Duration <= 0 {
+		_, _ = fmt.Fprintf(
+			os.Stderr, "Warning: Invalid connect_timeout in config. Using '%s' as default.\n", defaultConnectTimeout,
+		)
+		config.ConnectTimeout = Duration{defaultConnectTimeout}
+	}
+
+	// Validate endpoint overrides, dropping the ones that can't be used
+	for name, endpoint := range config.Endpoints {
+		if _, ok := LookupProvider(name); !ok {
+			_, _ = fmt.Fprintf(os.Stderr, "Warning: Ignoring endpoints of unknown provider '%s'.\n", name)
+			delete(config.Endpoints, name)
+			continue
+		}
+		for _, u := range []*string{&endpoint.BaseURL, &endpoint.GeocodingURL} {
+			if *u == "" {
+				continue
+			}
+			if parsed, err := url.Parse(*u); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+				_, _ = fmt.Fprintf(os.Stderr, "Warning: Ignoring invalid URL '%s' for %s.\n", *u, name)
+				*u = ""
+			}
+		}
+		config.Endpoints[name] = endpoint
+	}
+
+	// Validate TLS settings
+	if config.InsecureSkipVerify {
+		_, _ = fmt.Fprintln(os.Stderr, "Warning: TLS certificate verification is disabled by 'insecure_skip_verify'.")
+	}
+
+	// Validate retries
+	if config.Retries < 0 {
+		_, _ = fmt.Fprintln(os.Stderr, "Warning: Invalid retries in config. Not retrying failed requests.")
+		config.Retries = 0
 	}
 
 	// Validate units
-	if !slices.Contains(validUnits[:], config.Units) {
-		_, _ = fmt.Fprintf(os.Stderr, "Warning: Invalid units in config. Using '%s' as default.\n", defaultUnit)
-		config.Units = defaultUnit
+	for _, field := range config.Units.fields() {
+		field.validate(field.value)
 	}
 
 	// Validate API key requirement
-	if config.Provider == ProviderOpenWeatherMap && config.ApiKey == "" {
-		_, _ = fmt.Fprintf(os.Stderr, "Warning: 'api_key' is required for %s provider.\n", ProviderOpenWeatherMap)
+	if len(config.Providers) == 0 && provider.Capabilities().RequiresAPIKey && config.ApiKey == "" {
+		_, _ = fmt.Fprintf(os.Stderr, "Warning: 'api_key' is required for %s provider.\n", config.Provider)
 	}
+	for _, name := range config.Providers {
+		if p, _ := LookupProvider(name); p.Capabilities().RequiresAPIKey && config.ApiKey == "" {
+			_, _ = fmt.Fprintf(os.Stderr, "Warning: 'api_key' is required for %s provider.\n", name)
+		}
+	}
+
+	// Build the HTTP client once for all requests. Falling back to a default one would silently
+	// bypass the proxy or CA the user asked for, so invalid network settings are an error.
+	if config.HTTPClient == nil {
+		client, err := NewHTTPClient(*config)
+		if err != nil {
+			return err
+		}
+		config.HTTPClient = client
+	}
+	return nil
+}
+
+// ReadConfig reads/creates the config file and returns the validated configuration
+func ReadConfig() (Config, error) {
+	config := readConfig()
+	err := ValidateConfig(&config)
+	return config, err
 }
 
-// ReadConfig reads/creates the config file and returns the configuration
-func ReadConfig() Config {
+// readConfig reads/creates the config file, falling back to the defaults
+func readConfig() Config {
 	configPath := GetConfigPath()
 	defaultConfig := DefaultConfig()
 	if configPath == "" {
@@ -131,8 +571,8 @@ func ReadConfig() Config {
 		return defaultConfig
 	}
 
-	// Read existing config
-	var config Config
+	// Read existing config, keys missing from older config files keep their defaults
+	config := DefaultConfig()
 	data, err := os.ReadFile(configPath)
 	if err != nil {
 		_, _ = fmt.Fprintln(os.Stderr, "Failed to read config file:", err)
@@ -150,14 +590,33 @@ func ReadConfig() Config {
 			if provider, ok := partialConfig["provider"].(string); ok {
 				defaultConfig.Provider = provider
 			}
+			if providers, ok := partialConfig["providers"].([]any); ok {
+				for _, p := range providers {
+					if name, ok := p.(string); ok {
+						defaultConfig.Providers = append(defaultConfig.Providers, name)
+					}
+				}
+			}
 			if apiKey, ok := partialConfig["api_key"].(string); ok {
 				defaultConfig.ApiKey = apiKey
 			}
 			if city, ok := partialConfig["city"].(string); ok {
 				defaultConfig.City = city
 			}
-			if units, ok := partialConfig["units"].(string); ok {
-				defaultConfig.Units = units
+			if latitude, ok := tomlFloat(partialConfig["latitude"]); ok {
+				defaultConfig.Latitude = &latitude
+			}
+			if longitude, ok := tomlFloat(partialConfig["longitude"]); ok {
+				defaultConfig.Longitude = &longitude
+			}
+			if label, ok := partialConfig["label"].(string); ok {
+				defaultConfig.Label = label
+			}
+			if unitsValue, ok := partialConfig["units"]; ok {
+				configured := defaultConfig.Units
+				if configured.UnmarshalTOML(unitsValue) == nil {
+					defaultConfig.Units = configured
+				}
 			}
 			if showCityName, ok := partialConfig["showcityname"].(bool); ok {
 				defaultConfig.ShowCityName = showCityName
@@ -171,6 +630,49 @@ func ReadConfig() Config {
 			if compact, ok := partialConfig["compact"].(bool); ok {
 				defaultConfig.Compact = compact
 			}
+			if showTrend, ok := partialConfig["show_trend"].(bool); ok {
+				defaultConfig.ShowTrend = showTrend
+			}
+			if ensemble, ok := partialConfig["ensemble"].(bool); ok {
+				defaultConfig.Ensemble = ensemble
+			}
+			if maxAge, ok := partialConfig["cache_max_age"].(string); ok {
+				if d, err := time.ParseDuration(maxAge); err == nil {
+					defaultConfig.CacheMaxAge = Duration{d}
+				}
+			}
+			if timeout, ok := partialConfig["timeout"].(string); ok {
+				if d, err := time.ParseDuration(timeout); err == nil {
+					defaultConfig.Timeout = Duration{d}
+				}
+			}
+			if connectTimeout, ok := partialConfig["connect_timeout"].(string); ok {
+				if d, err := time.ParseDuration(connectTimeout); err == nil {
+					defaultConfig.ConnectTimeout = Duration{d}
+				}
+			}
+			if retries, ok := partialConfig["retries"].(int64); ok {
+				defaultConfig.Retries = int(retries)
+			}
+			if proxy, ok := partialConfig["proxy"].(string); ok {
+				defaultConfig.Proxy = proxy
+			}
+			if caFile, ok := partialConfig["ca_file"].(string); ok {
+				defaultConfig.CAFile = caFile
+			}
+			if insecureSkipVerify, ok := partialConfig["insecure_skip_verify"].(bool); ok {
+				defaultConfig.InsecureSkipVerify = insecureSkipVerify
+			}
+			if endpoints, ok := partialConfig["endpoints"].(map[string]any); ok {
+				defaultConfig.Endpoints = make(map[string]Endpoint, len(endpoints))
+				for name, e := range endpoints {
+					if fields, ok := e.(map[string]any); ok {
+						baseURL, _ := fields["base_url"].(string)
+						geocodingURL, _ := fields["geocoding_url"].(string)
+						defaultConfig.Endpoints[name] = Endpoint{BaseURL: baseURL, GeocodingURL: geocodingURL}
+					}
+				}
+			}
 		}
 
 		// Write corrected config back
@@ -183,12 +685,21 @@ func ReadConfig() Config {
 		config = defaultConfig
 	}
 
-	// Validate configuration
-	ValidateConfig(&config)
-
 	return config
 }
 
+// tomlFloat converts a decoded TOML number, which is an int64 when written without decimals
+func tomlFloat(v any) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case int64:
+		return float64(n), true
+	default:
+		return 0, false
+	}
+}
+
 // WriteConfig writes the given configuration into the specified location
 func WriteConfig(config Config, configPath string) error {
 	// Create the directory if it doesn't exist
@@ -215,11 +726,77 @@ func WriteConfig(config Config, configPath string) error {
 	return nil
 }
 
+// presetFlag returns the parser of the --units flag, rejecting unknown presets
+func presetFlag(preset *string) func(string) error {
+	return func(s string) error {
+		if !slices.Contains(validUnits[:], s) {
+			return fmt.Errorf("unknown units %q, expected one of %s", s, strings.Join(validUnits[:], ", "))
+		}
+		*preset = s
+		return nil
+	}
+}
+
+// unitFlag returns the parser of a unit override flag, storing the unit in its canonical spelling
+func unitFlag[U ~string](unit *string, parse func(string) (U, error)) func(string) error {
+	return func(s string) error {
+		parsed, err := parse(s)
+		*unit = string(parsed)
+		return err
+	}
+}
+
 // ParseFlags parses command line flags
 func ParseFlags() (flags Flags) {
 	flag.StringVar(&flags.City, "city", "", "City to get weather for")
-	flag.StringVar(&flags.Units, "units", "", fmt.Sprintf("Units (%s)", strings.Join(validUnits[:], ", ")))
+	flag.Func("lat", "Latitude to get weather for, skips geocoding (requires --lon)", func(s string) error {
+		latitude, err := strconv.ParseFloat(s, 64)
+		flags.Latitude = &latitude
+		return err
+	})
+	flag.Func("lon", "Longitude to get weather for, skips geocoding (requires --lat)", func(s string) error {
+		longitude, err := strconv.ParseFloat(s, 64)
+		flags.Longitude = &longitude
+		return err
+	})
+	flag.Func("coords", "Coordinates to get weather for as \"latitude,longitude\", skips geocoding", func(s string) error {
+		latitude, longitude, err := ParseCoordinates(s)
+		flags.Latitude, flags.Longitude = &latitude, &longitude
+		return err
+	})
+	flag.StringVar(&flags.Label, "label", "", "Name to display for the location given by coordinates")
+	flag.Func(
+		"units", fmt.Sprintf("Unit `preset` (%s)", strings.Join(validUnits[:], ", ")), presetFlag(&flags.Units),
+	)
+	flag.Func(
+		"temperature-unit", "Temperature `unit` (C, F, K)", unitFlag(&flags.TemperatureUnit, units.ParseTemperature),
+	)
+	flag.Func(
+		"wind-unit", "Wind speed `unit` (km/h, mph, m/s, knots, Beaufort)", unitFlag(&flags.WindUnit, units.ParseSpeed),
+	)
+	flag.Func(
+		"pressure-unit", "Pressure `unit` (hPa, inHg, mmHg, kPa)", unitFlag(&flags.PressureUnit, units.ParsePressure),
+	)
+	flag.Func(
+		"precipitation-unit", "Precipitation `unit` (mm, in)",
+		unitFlag(&flags.PrecipitationUnit, units.ParsePrecipitation),
+	)
+	flag.Func("distance-unit", "Distance `unit` (km, mi, m)", unitFlag(&flags.DistanceUnit, units.ParseDistance))
 	flag.BoolVar(&flags.Compact, "compact", false, "Compact display mode")
+	flag.BoolVar(&flags.Trend, "trend", false, "Show the temperature trend over the next 24 hours")
+	flag.Var(
+		hoursFlag{&flags.Hourly}, "hourly",
+		fmt.Sprintf("Show the forecast for the next `N` hours (default %d)", DefaultForecastHours),
+	)
+	flag.IntVar(&flags.Days, "days", 0, fmt.Sprintf("Show the forecast for `N` days from today (1-%d)", MaxForecastDays))
+	flag.BoolVar(&flags.Ensemble, "ensemble", false, "Query all providers and merge their readings")
+	flag.BoolVar(&flags.RefreshLocation, "refresh-location", false, "Geocode the city again instead of using the cache")
+	flag.BoolVar(&flags.Offline, "offline", false, "Show the last known weather without using the network")
+	flag.BoolVar(&flags.Verbose, "verbose", false, "Log requests, cache hits and chosen providers to stderr")
+	flag.BoolVar(&flags.Debug, "debug", false, "Log even more details than --verbose to stderr")
+	flag.StringVar(
+		&flags.LogFormat, "log-format", "", fmt.Sprintf("Log format (%s, %s)", LogFormatText, LogFormatJSON),
+	)
 	flag.BoolVar(&flags.Help, "help", false, "Show help")
 	flag.BoolVar(&flags.Version, "version", false, "Show version information")
 
@@ -228,16 +805,57 @@ func ParseFlags() (flags Flags) {
 		_, _ = fmt.Fprintf(os.Stderr, "Usage: %s [options]\n\n", os.Args[0])
 		_, _ = fmt.Fprintln(os.Stderr, "Options:")
 		flag.PrintDefaults()
+		_, _ = fmt.Fprintln(os.Stderr, "\nSetting STORMY_DEBUG=1 (or STORMY_DEBUG=json) enables debug logging as well.")
 		_, _ = fmt.Fprintln(os.Stderr, "\nConfig file is located at:", GetConfigPath())
 	}
 
 	flag.Parse()
 
+	// The flag package leaves the optional number of --hourly among the arguments
+	if flags.Hourly > 0 && flag.NArg() > 0 {
+		if previous := os.Args[len(os.Args)-flag.NArg()-1]; strings.TrimLeft(previous, "-") == "hourly" {
+			if err := flag.Set("hourly", flag.Arg(0)); err != nil {
+				_, _ = fmt.Fprintf(os.Stderr, "invalid value %q for flag -hourly: %v\n", flag.Arg(0), err)
+				flag.Usage()
+				os.Exit(2)
+			}
+			_ = flag.CommandLine.Parse(flag.Args()[1:])
+		}
+	}
+
 	if flags.Help {
 		flag.Usage()
 		os.Exit(0)
 	}
 
+	if flags.LogFormat != "" && flags.LogFormat != LogFormatText && flags.LogFormat != LogFormatJSON {
+		_, _ = fmt.Fprintf(os.Stderr, "Invalid log format %q\n", flags.LogFormat)
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if flags.Days < 0 || flags.Days > MaxForecastDays {
+		_, _ = fmt.Fprintf(os.Stderr, "Invalid number of days %d, expected 1 to %d\n", flags.Days, MaxForecastDays)
+		os.Exit(2)
+	}
+	if flags.Days > 0 && flags.Hourly > 0 {
+		_, _ = fmt.Fprintln(os.Stderr, "--hourly and --days can't be combined")
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if (flags.Latitude == nil) != (flags.Longitude == nil) {
+		_, _ = fmt.Fprintln(os.Stderr, "Both --lat and --lon must be provided")
+		flag.Usage()
+		os.Exit(2)
+	}
+	if flags.Latitude != nil {
+		if err := validateCoordinates(*flags.Latitude, *flags.Longitude); err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "Invalid coordinates: %v\n", err)
+			os.Exit(2)
+		}
+	}
+
 	return
 }
 
@@ -245,12 +863,39 @@ func ParseFlags() (flags Flags) {
 func ApplyFlags(config *Config, flags Flags) {
 	if flags.City != "" {
 		config.City = flags.City
+		// An explicit city wins over coordinates pinned in the config
+		config.Latitude, config.Longitude, config.Label, config.ResolvedCity = nil, nil, "", ""
 	}
-	if flags.Units != "" {
-		config.Units = flags.Units
-		ValidateConfig(config)
+	if flags.Latitude != nil {
+		config.Latitude, config.Longitude = flags.Latitude, flags.Longitude
+		config.Label, config.ResolvedCity = "", ""
+	}
+	if flags.Label != "" {
+		config.Label = flags.Label
+	}
+	// The unit flags were validated by ParseFlags, rejecting invalid ones
+	unitFlags := [...]string{
+		flags.Units, flags.TemperatureUnit, flags.WindUnit,
+		flags.PressureUnit, flags.PrecipitationUnit, flags.DistanceUnit,
+	}
+	for i, field := range config.Units.fields() {
+		if unitFlags[i] != "" {
+			*field.value = unitFlags[i]
+		}
 	}
 	if flags.Compact {
 		config.Compact = true
 	}
+	if flags.Trend {
+		config.ShowTrend = true
+	}
+	if flags.Ensemble {
+		config.Ensemble = true
+	}
+	if flags.RefreshLocation {
+		config.RefreshLocation = true
+	}
+	if flags.Offline {
+		config.Offline = true
+	}
 }
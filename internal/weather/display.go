@@ -3,17 +3,15 @@ package weather
 import (
 	"fmt"
 	"math"
+	"slices"
 	"strings"
+	"time"
+	"unicode/utf8"
 
+	"github.com/ashish0kumar/stormy/internal/units"
 	"github.com/fatih/color"
 )
 
-const (
-	MpsToMph = 2.23694
-	KphToMph = 0.621371
-	MpsToKph = 3.6
-)
-
 type Condition = string
 
 const (
@@ -43,10 +41,23 @@ const (
 	ConditionTornado      Condition = "Tornado"
 )
 
+// staleAfter is the age from which a reading is shown with its age
+const staleAfter = time.Minute
+
 var directionSymbols = [...]string{"↑", "↗", "→", "↘", "↓", "↙", "←", "↖"}
 
-func celsiusToFahrenheit(c float64) float64 {
-	return c*9/5 + 32
+// FormatAge describes how long ago something happened, like "3h ago"
+func FormatAge(age time.Duration) string {
+	switch {
+	case age < time.Minute:
+		return "just now"
+	case age < time.Hour:
+		return fmt.Sprintf("%dm ago", int(age.Minutes()))
+	case age < 48*time.Hour:
+		return fmt.Sprintf("%dh ago", int(age.Hours()))
+	default:
+		return fmt.Sprintf("%dd ago", int(age.Hours()/24))
+	}
 }
 
 // getWindDirectionSymbol converts wind degrees to a direction symbol
@@ -55,8 +66,9 @@ func getWindDirectionSymbol(degrees int) string {
 	return directionSymbols[index]
 }
 
-// DisplayWeather renders the weather data with ASCII art
-func DisplayWeather(weather *Weather, config Config) {
+// DisplayWeather renders the weather data with ASCII art and returns the number of printed lines.
+// The temperature of the outlook is charted next to it, unless the outlook is nil.
+func DisplayWeather(weather *Weather, outlook *Forecast, config Config) int {
 	// Get the main weather condition
 	mainWeather := ConditionUnknown
 	description := "unknown conditions"
@@ -67,42 +79,35 @@ func DisplayWeather(weather *Weather, config Config) {
 		weatherID = weather.Weather[0].ID
 	}
 
-	// Determine units based on config
-	var windSpeedUnits, tempUnit string
-
-	// Convert wind speed based on provider and units
-	windSpeed := weather.Wind.Speed
-	temperature := weather.Main.Temp
-
-	switch config.Units {
-	case UnitImperial:
-		windSpeedUnits = "mph"
-		tempUnit = "°F"
-
-		// Convert temperature for both providers
-		temperature = celsiusToFahrenheit(temperature)
-
-		// Convert wind speed based on this provider
-		if config.Provider == ProviderOpenWeatherMap {
-			windSpeed *= MpsToMph // m/s to mph
-		} else {
-			windSpeed *= KphToMph // km/h to mph
-		}
-
-	default:
-		windSpeedUnits = "km/h"
-		tempUnit = "°C"
-
-		// Convert wind speed for OpenWeatherMap provider
-		if config.Provider == ProviderOpenWeatherMap {
-			windSpeed *= MpsToKph // m/s to km/h
+	// Convert the normalized readings to the configured units
+	unitSet := config.DisplayUnits()
+	temperature := unitSet.Temperature.Convert(weather.Main.Temp)
+	tempUnit := unitSet.Temperature.Symbol()
+	windSpeed := unitSet.Speed.Convert(weather.Wind.Speed)
+	windSpeedUnits := unitSet.Speed.Symbol()
+	windSpeedDecimals := unitSet.Speed.Decimals()
+	precipitation := unitSet.Precipitation.Convert(weather.Rain.OneHour)
+	precipitationUnits := unitSet.Precipitation.Symbol()
+	precipitationDecimals := unitSet.Precipitation.Decimals()
+
+	// Pressure and visibility are only shown when the provider reports them
+	formatPressure := func(separator string) string {
+		if weather.Main.Pressure == nil {
+			return ""
 		}
+		return fmt.Sprintf(
+			"%.*f%s%s", unitSet.Pressure.Decimals(),
+			unitSet.Pressure.Convert(*weather.Main.Pressure), separator, unitSet.Pressure.Symbol(),
+		)
 	}
-
-	// Format precipitation info
-	precipitationMM := 0.0
-	if weather.Rain.OneHour > 0 {
-		precipitationMM = weather.Rain.OneHour
+	formatVisibility := func(separator string) string {
+		if weather.Visibility == nil {
+			return ""
+		}
+		return fmt.Sprintf(
+			"%.*f%s%s", unitSet.Distance.Decimals(),
+			unitSet.Distance.Convert(*weather.Visibility), separator, unitSet.Distance.Symbol(),
+		)
 	}
 
 	popPercent := 0
@@ -110,7 +115,36 @@ func DisplayWeather(weather *Weather, config Config) {
 		popPercent = int(math.Round(weather.Pop * 100))
 	}
 
-	labels := make([]string, 0, 6)
+	// Show how far apart the providers were in ensemble mode
+	tempSpread := ""
+	if weather.Ensemble != nil {
+		tempSpread = fmt.Sprintf(" ±%.1f", unitSet.Temperature.ConvertDelta(weather.Ensemble.TempSpread))
+	}
+
+	// Name the providers of an ensemble, or the one that answered when falling back between several
+	sourceLabel, source := "Source ", ""
+	if weather.Ensemble != nil {
+		sourceLabel = "Sources "
+		source = formatEnsembleMembers(weather.Ensemble, config.UseColors)
+	} else if len(config.ProviderChain()) > 1 {
+		source = weather.Provider
+	}
+
+	// Tell how old the reading is when it was served from the cache, or clearly
+	// mark it when it's only the last known one
+	ageLabel, age := "Updated ", ""
+	if weather.Offline {
+		ageLabel, age = "Offline ", "as of "+FormatAge(time.Since(weather.FetchedAt))
+	} else if !weather.FetchedAt.IsZero() && time.Since(weather.FetchedAt) >= staleAfter {
+		age = FormatAge(time.Since(weather.FetchedAt))
+	}
+
+	trend := ""
+	if outlook != nil && len(outlook.Hourly) > 0 {
+		trend = temperatureSparkline(outlook.Hourly, unitSet, config.UseColors)
+	}
+
+	labels := make([]string, 0, 8)
 	values := make([]string, 0, cap(labels))
 
 	// City name display
@@ -134,43 +168,84 @@ func DisplayWeather(weather *Weather, config Config) {
 		values = append(values, description)
 
 		labels = append(labels, "Temp ")
-		values = append(values, fmt.Sprintf("%.1f%s", temperature, tempUnit))
+		values = append(values, fmt.Sprintf("%.1f%s%s", temperature, tempUnit, tempSpread))
+
+		if trend != "" {
+			labels = append(labels, "Next 24h ")
+			values = append(values, trend)
+		}
 
 		labels = append(labels, "Wind ")
 		values = append(
-			values, fmt.Sprintf("%.1f %s %s", windSpeed, windSpeedUnits, getWindDirectionSymbol(weather.Wind.Deg)),
+			values,
+			fmt.Sprintf(
+				"%.*f %s %s", windSpeedDecimals, windSpeed, windSpeedUnits, getWindDirectionSymbol(weather.Wind.Deg),
+			),
 		)
 
 		labels = append(labels, "Humidity ")
 		values = append(values, fmt.Sprintf("%d%%", weather.Main.Humidity))
 
 		labels = append(labels, "Precip ")
-		values = append(values, fmt.Sprintf("%.1f mm | %d%%", precipitationMM, popPercent))
+		values = append(
+			values,
+			fmt.Sprintf("%.*f %s | %d%%", precipitationDecimals, precipitation, precipitationUnits, popPercent),
+		)
+
+		if pressure := formatPressure(" "); pressure != "" {
+			labels = append(labels, "Pressure ")
+			values = append(values, pressure)
+		}
+
+		if visibility := formatVisibility(" "); visibility != "" {
+			labels = append(labels, "Visibility ")
+			values = append(values, visibility)
+		}
+
+		if source != "" {
+			labels = append(labels, sourceLabel)
+			values = append(values, source)
+		}
+
+		if age != "" {
+			labels = append(labels, ageLabel)
+			values = append(values, age)
+		}
 	} else {
 		// Compact mode doesn't use labels in the same way
 		weatherDisplay := description
-		tempDisplay := fmt.Sprintf("%.1f%s", temperature, tempUnit)
-		windDisplay := fmt.Sprintf("%.1f%s %s", windSpeed, windSpeedUnits, getWindDirectionSymbol(weather.Wind.Deg))
+		tempDisplay := fmt.Sprintf("%.1f%s%s", temperature, tempUnit, tempSpread)
+		windDisplay := fmt.Sprintf(
+			"%.*f%s %s", windSpeedDecimals, windSpeed, windSpeedUnits, getWindDirectionSymbol(weather.Wind.Deg),
+		)
 		humidityDisplay := fmt.Sprintf("%d%%", weather.Main.Humidity)
-		precipitationDisplay := fmt.Sprintf("%.1fmm | %d%%", precipitationMM, popPercent)
+		precipitationDisplay := fmt.Sprintf(
+			"%.*f%s | %d%%", precipitationDecimals, precipitation, precipitationUnits, popPercent,
+		)
+		pressureDisplay := formatPressure("")
+		visibilityDisplay := formatVisibility("")
 
 		// For compact mode, we'll just pass these values directly to the display function
-		displayWeatherArtCompact(
+		return displayWeatherArtCompact(
 			mainWeather,
 			weatherID,
 			cityName,
 			weatherDisplay,
 			tempDisplay,
+			trend,
 			windDisplay,
 			humidityDisplay,
 			precipitationDisplay,
+			pressureDisplay,
+			visibilityDisplay,
+			source,
+			age,
 			config,
 		)
-		return
 	}
 
 	// For standard mode, display with aligned labels and values
-	displayWeatherArtAligned(mainWeather, weatherID, labels, values, config)
+	return displayWeatherArtAligned(mainWeather, weatherID, labels, values, config)
 }
 
 // getColoredWeatherText returns a colored weather text based on the condition
@@ -182,19 +257,36 @@ func getColoredWeatherText(mainWeather, description string) string {
 		return color.YellowString(color.New(color.Bold).Sprintf(text))
 	case ConditionClouds:
 		return color.MagentaString(color.New(color.Bold).Sprintf(text))
-	case ConditionRain:
+	case ConditionRain, ConditionDrizzle:
 		return color.BlueString(color.New(color.Bold).Sprintf(text))
 	case ConditionSnow:
 		return color.CyanString(color.New(color.Bold).Sprintf(text))
 	case ConditionThunderstorm:
 		return color.New(color.Bold, color.BgRed).Sprintf(text)
+	case ConditionFog, ConditionMist, ConditionHaze, ConditionSmoke, ConditionDust, ConditionSand, ConditionAsh:
+		return color.WhiteString(color.New(color.Bold).Sprintf(text))
 	default:
 		return color.RedString(color.New(color.Bold).Sprintf(text))
 	}
 }
 
+// formatEnsembleMembers lists the providers of an ensemble reading, flagging outliers
+func formatEnsembleMembers(info *EnsembleInfo, useColors bool) string {
+	names := make([]string, len(info.Members))
+	for i, member := range info.Members {
+		names[i] = member.Provider
+		if member.Outlier {
+			names[i] += " (outlier)"
+			if useColors {
+				names[i] = color.RedString(names[i])
+			}
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
 // displayWeatherArtAligned shows ASCII art with vertically aligned labels and values
-func displayWeatherArtAligned(mainWeather string, weatherID int, labels, values []string, config Config) {
+func displayWeatherArtAligned(mainWeather string, weatherID int, labels, values []string, config Config) int {
 	// Get the weather icon
 	iconLines := getWeatherIcon(mainWeather, weatherID, config.UseColors)
 
@@ -217,18 +309,24 @@ func displayWeatherArtAligned(mainWeather string, weatherID int, labels, values
 		}
 
 		if config.UseColors {
-			switch i {
-			case 0: // City
+			switch strings.TrimSpace(labels[i]) {
+			case "City":
 				coloredValues[i] = color.GreenString(color.New(color.Bold).Sprintf(value))
-			case 1: // Weather description
+			case "Weather":
 				coloredValues[i] = getColoredWeatherText(mainWeather, value)
-			case 2: // Temperature
+			case "Temp":
 				coloredValues[i] = color.RedString(value)
-			case 3: // Wind
+			case "Wind":
 				coloredValues[i] = color.GreenString(value)
-			case 4: // Humidity
+			case "Humidity":
 				coloredValues[i] = color.CyanString(value)
-			case 5: // Precipitation
+			case "Pressure":
+				coloredValues[i] = color.MagentaString(value)
+			case "Visibility":
+				coloredValues[i] = color.WhiteString(value)
+			case "Updated", "Offline":
+				coloredValues[i] = color.YellowString(value)
+			case "Precip":
 				parts := strings.Split(value, "|")
 				if len(parts) == 2 {
 					coloredValues[i] = color.BlueString(strings.TrimSpace(parts[0])) + " | " + color.CyanString(strings.TrimSpace(parts[1]))
@@ -262,22 +360,15 @@ func displayWeatherArtAligned(mainWeather string, weatherID int, labels, values
 
 	textLines = append(textLines, "") // Empty line to match icon bottom spacing
 
-	// Print icon and text lines together
-	for i := 0; i < len(iconLines); i++ {
-		iconLine := iconLines[i]
-		textLine := ""
-		if i < len(textLines) {
-			textLine = textLines[i]
-		}
-		fmt.Printf("%s  %s\n", iconLine, textLine)
-	}
+	return printIconWithText(iconLines, textLines)
 }
 
 // displayWeatherArtCompact shows ASCII art with compact formatting
 func displayWeatherArtCompact(
 	mainWeather string, weatherID int, cityName, weatherDisplay,
-	tempDisplay, windDisplay, humidityDisplay, precipDisplay string, config Config,
-) {
+	tempDisplay, trend, windDisplay, humidityDisplay, precipDisplay, pressureDisplay, visibilityDisplay,
+	source, age string, config Config,
+) int {
 
 	// Get the weather icon
 	iconLines := getWeatherIcon(mainWeather, weatherID, config.UseColors)
@@ -298,6 +389,13 @@ func displayWeatherArtCompact(
 		} else {
 			precipDisplay = color.BlueString(precipDisplay)
 		}
+
+		if pressureDisplay != "" {
+			pressureDisplay = color.MagentaString(pressureDisplay)
+		}
+		if visibilityDisplay != "" {
+			visibilityDisplay = color.WhiteString(visibilityDisplay)
+		}
 	}
 
 	// Prepare the text lines
@@ -308,21 +406,378 @@ func displayWeatherArtCompact(
 		textLines = append(textLines, cityName)
 	}
 
-	textLines = append(textLines, weatherDisplay, tempDisplay, windDisplay, humidityDisplay)
+	textLines = append(textLines, weatherDisplay, tempDisplay)
+
+	if trend != "" {
+		textLines = append(textLines, trend)
+	}
+
+	textLines = append(textLines, windDisplay, humidityDisplay)
 
 	if precipDisplay != "" {
 		textLines = append(textLines, precipDisplay)
 	}
 
+	// Pressure and visibility share a line, either may be missing
+	if atmosphere := slices.DeleteFunc([]string{pressureDisplay, visibilityDisplay}, func(value string) bool {
+		return value == ""
+	}); len(atmosphere) > 0 {
+		textLines = append(textLines, strings.Join(atmosphere, " | "))
+	}
+
+	if source != "" {
+		textLines = append(textLines, source)
+	}
+
+	if age != "" {
+		if config.UseColors {
+			age = color.YellowString(age)
+		}
+		textLines = append(textLines, age)
+	}
+
 	textLines = append(textLines, "") // Empty line to match icon bottom spacing
 
-	// Print icon and text lines together
-	for i := 0; i < len(iconLines); i++ {
-		iconLine := iconLines[i]
+	return printIconWithText(iconLines, textLines)
+}
+
+// printIconWithText prints the icon and text lines side by side and returns the number of printed lines.
+// Text running past the icon is kept aligned, except for the trailing spacing line.
+func printIconWithText(iconLines, textLines []string) int {
+	rows := len(iconLines)
+	if len(textLines) > rows && textLines[len(textLines)-1] == "" {
+		textLines = textLines[:len(textLines)-1]
+	}
+	rows = max(rows, len(textLines))
+
+	blankIcon := strings.Repeat(" ", iconWidth)
+	for i := 0; i < rows; i++ {
+		iconLine := blankIcon
+		if i < len(iconLines) {
+			iconLine = iconLines[i]
+		}
 		textLine := ""
 		if i < len(textLines) {
 			textLine = textLines[i]
 		}
 		fmt.Printf("%s  %s\n", iconLine, textLine)
 	}
+
+	return rows
+}
+
+// DisplayHourlyForecast renders the forecast as a table with a row per step and returns
+// the number of printed lines
+func DisplayHourlyForecast(forecast *Forecast, config Config) int {
+	unitSet := config.DisplayUnits()
+	location := time.Local
+	if forecast.Timezone != nil {
+		location = forecast.Timezone.Location()
+	}
+
+	header := []string{"Time", "", "Temp", "Precip", "Wind"}
+	if !config.Compact {
+		header[1] = "Weather"
+	}
+	rows := [][]string{header}
+	conditions := make([]string, 0, len(forecast.Hourly))
+
+	// Only name the day on the first row and when it changes
+	lastDay := ""
+	for _, step := range forecast.Hourly {
+		localTime := step.Time.In(location)
+		day := localTime.Format("Mon")
+		if day == lastDay {
+			day = ""
+		} else {
+			lastDay = day
+		}
+
+		weather := getWeatherGlyph(step.Condition.Main, step.Condition.ID)
+		if !config.Compact {
+			weather += " " + step.Condition.Description
+		}
+
+		precipitation := "   –"
+		if step.Pop != nil {
+			precipitation = fmt.Sprintf("%3d%%", int(math.Round(*step.Pop*100)))
+		}
+		if step.Precipitation > 0 {
+			precipitation += fmt.Sprintf(
+				" %.*f %s", unitSet.Precipitation.Decimals(),
+				unitSet.Precipitation.Convert(step.Precipitation), unitSet.Precipitation.Symbol(),
+			)
+		}
+
+		rows = append(rows, []string{
+			fmt.Sprintf("%-3s %s", day, localTime.Format("15:04")),
+			weather,
+			fmt.Sprintf("%.1f%s", unitSet.Temperature.Convert(step.Temp), unitSet.Temperature.Symbol()),
+			precipitation,
+			fmt.Sprintf(
+				"%.*f %s %s", unitSet.Speed.Decimals(), unitSet.Speed.Convert(step.Wind.Speed),
+				unitSet.Speed.Symbol(), getWindDirectionSymbol(step.Wind.Deg),
+			),
+		})
+		conditions = append(conditions, step.Condition.Main)
+	}
+
+	// Size the columns on their visible width, before any color is applied
+	widths := make([]int, len(header))
+	for _, row := range rows {
+		for i, cell := range row {
+			widths[i] = max(widths[i], utf8.RuneCountInString(cell))
+		}
+	}
+
+	lines := 0
+	if title := forecastTitle(forecast, config); title != "" {
+		fmt.Println(title)
+		lines++
+	}
+
+	for r, row := range rows {
+		cells := make([]string, len(row))
+		for i, cell := range row {
+			padding := strings.Repeat(" ", widths[i]-utf8.RuneCountInString(cell))
+			// Right-align the numbers
+			if i == 2 || i == 4 {
+				cell = padding + cell
+				padding = ""
+			}
+
+			if config.UseColors {
+				switch {
+				case r == 0:
+					cell = color.BlueString(cell)
+				case i == 1:
+					cell = getColoredWeatherText(conditions[r-1], cell)
+				case i == 2:
+					cell = color.RedString(cell)
+				case i == 3:
+					cell = color.CyanString(cell)
+				case i == 4:
+					cell = color.GreenString(cell)
+				}
+			}
+			cells[i] = cell + padding
+		}
+		fmt.Println(strings.TrimRight(strings.Join(cells, "  "), " "))
+		lines++
+	}
+
+	for _, line := range forecastCharts(forecast.Hourly, unitSet, config.UseColors) {
+		fmt.Println(line)
+		lines++
+	}
+
+	return lines
+}
+
+// forecastTitle names the location of a forecast, the provider when falling back between
+// several and how old the forecast is when it was served from the cache
+func forecastTitle(forecast *Forecast, config Config) string {
+	parts := make([]string, 0, 3)
+	if config.ShowCityName {
+		cityName := forecast.Name
+		if cityName == "" {
+			cityName = config.City
+		}
+		if config.UseColors {
+			cityName = color.GreenString(color.New(color.Bold).Sprint(cityName))
+		}
+		parts = append(parts, cityName)
+	}
+	if len(config.ProviderChain()) > 1 {
+		parts = append(parts, forecast.Provider)
+	}
+	if !forecast.FetchedAt.IsZero() && time.Since(forecast.FetchedAt) >= staleAfter {
+		age := "updated " + FormatAge(time.Since(forecast.FetchedAt))
+		if config.UseColors {
+			age = color.YellowString(age)
+		}
+		parts = append(parts, age)
+	}
+	return strings.Join(parts, " · ")
+}
+
+// dayPartTextWidth is the width of the text next to the icon in the columns of the daily forecast
+const dayPartTextWidth = 15
+
+// DisplayDailyForecast renders a block per day of the forecast with a column per part of
+// the day, in the layout of wttr.in, and returns the number of printed lines
+func DisplayDailyForecast(forecast *Forecast, config Config) int {
+	unitSet := config.DisplayUnits()
+	location := forecast.Location()
+
+	// Compact mode leaves out the icons
+	columnWidth := dayPartTextWidth + 2
+	if !config.Compact {
+		columnWidth += iconWidth + 1
+	}
+	border := func(left, middle, right string) string {
+		segments := make([]string, len(DayParts))
+		for i := range segments {
+			segments[i] = strings.Repeat("─", columnWidth)
+		}
+		return left + strings.Join(segments, middle) + right
+	}
+
+	lines := 0
+	if title := forecastTitle(forecast, config); title != "" {
+		fmt.Println(title)
+		lines++
+	}
+
+	// Chart the hours of the days shown
+	if len(forecast.Daily) > 0 {
+		first, last := forecast.Daily[0], forecast.Daily[len(forecast.Daily)-1]
+		steps := slices.DeleteFunc(slices.Clone(forecast.Hourly), func(step ForecastStep) bool {
+			return step.Time.Before(first.Start) || !step.Time.Before(last.Start.Add(last.Duration))
+		})
+		for _, line := range forecastCharts(steps, unitSet, config.UseColors) {
+			fmt.Println(line)
+			lines++
+		}
+	}
+
+	for _, day := range forecast.Daily {
+		fmt.Println(formatDaySummary(day, location, unitSet, config.UseColors))
+
+		names := make([]string, len(DayParts))
+		for i, part := range DayParts {
+			padding := columnWidth - len(part.Name)
+			names[i] = strings.Repeat(" ", padding/2) + part.Name + strings.Repeat(" ", padding-padding/2)
+			if config.UseColors {
+				names[i] = color.BlueString(names[i])
+			}
+		}
+		fmt.Println(border("┌", "┬", "┐"))
+		fmt.Println("│" + strings.Join(names, "│") + "│")
+		fmt.Println(border("├", "┼", "┤"))
+
+		columns := make([][]string, len(DayParts))
+		for i := range DayParts {
+			part, ok := forecast.DayPart(day, i)
+			columns[i] = formatDayPart(part, ok, unitSet, config)
+		}
+		for row := range columns[0] {
+			cells := make([]string, len(columns))
+			for i, column := range columns {
+				cells[i] = column[row]
+			}
+			fmt.Println("│" + strings.Join(cells, "│") + "│")
+		}
+		fmt.Println(border("└", "┴", "┘"))
+
+		lines += len(columns[0]) + 5
+	}
+
+	return lines
+}
+
+// formatDaySummary describes a whole day of the forecast on a line headed by its date
+func formatDaySummary(day ForecastPeriod, location *time.Location, unitSet units.Set, useColors bool) string {
+	date := day.Start.In(location).Format("Mon 2 Jan")
+	temperature := formatTempRange(day.TempMin, day.TempMax, unitSet.Temperature)
+	precipitation := formatPeriodPrecipitation(day, unitSet.Precipitation)
+	wind := formatPeriodWind(day.Wind, unitSet.Speed)
+
+	description := day.Condition.Description
+	if useColors {
+		date = color.New(color.Bold).Sprint(date)
+		description = getColoredWeatherText(day.Condition.Main, description)
+		temperature = color.RedString(temperature)
+		precipitation = colorPrecipitation(precipitation)
+		wind = color.GreenString(wind)
+	}
+	return strings.Join([]string{date, description, temperature, precipitation, wind}, "  ")
+}
+
+// formatDayPart returns the lines of a day part column, each as wide as the column
+func formatDayPart(part ForecastPeriod, ok bool, unitSet units.Set, config Config) []string {
+	texts := []string{"–", "", "", "", ""}
+	if ok {
+		texts = []string{
+			part.Condition.Description,
+			formatTempRange(part.TempMin, part.TempMax, unitSet.Temperature),
+			formatPeriodWind(part.Wind, unitSet.Speed),
+			formatPeriodPrecipitation(part, unitSet.Precipitation),
+			"",
+		}
+	}
+
+	icon := make([]string, len(texts))
+	if ok && !config.Compact {
+		icon = getSmallWeatherIcon(part.Condition.Main, part.Condition.ID, config.UseColors)
+	}
+
+	lines := make([]string, len(texts))
+	for i, text := range texts {
+		text = truncateText(text, dayPartTextWidth)
+		padding := strings.Repeat(" ", dayPartTextWidth-utf8.RuneCountInString(text))
+		if config.UseColors && ok {
+			switch i {
+			case 0:
+				text = getColoredWeatherText(part.Condition.Main, text)
+			case 1:
+				text = color.RedString(text)
+			case 2:
+				text = color.GreenString(text)
+			case 3:
+				text = colorPrecipitation(text)
+			}
+		}
+
+		lines[i] = " " + text + padding + " "
+		if !config.Compact {
+			iconLine := icon[i]
+			if iconLine == "" {
+				iconLine = strings.Repeat(" ", iconWidth)
+			}
+			lines[i] = " " + iconLine + lines[i]
+		}
+	}
+	return lines
+}
+
+// formatTempRange formats the temperatures of a period in whole degrees
+func formatTempRange(tempMin, tempMax float64, unit units.Temperature) string {
+	low := fmt.Sprintf("%.0f", unit.Convert(tempMin))
+	high := fmt.Sprintf("%.0f", unit.Convert(tempMax))
+	if low == high {
+		return fmt.Sprintf("%s %s", low, unit.Symbol())
+	}
+	return fmt.Sprintf("%s–%s %s", low, high, unit.Symbol())
+}
+
+// formatPeriodPrecipitation formats the precipitation of a period and its probability when forecast
+func formatPeriodPrecipitation(period ForecastPeriod, unit units.Precipitation) string {
+	amount := fmt.Sprintf("%.*f %s", unit.Decimals(), unit.Convert(period.Precipitation), unit.Symbol())
+	if period.Pop == nil {
+		return amount
+	}
+	return fmt.Sprintf("%s | %d%%", amount, int(math.Round(*period.Pop*100)))
+}
+
+// formatPeriodWind formats the strongest wind of a period with its dominant direction
+func formatPeriodWind(wind Wind, unit units.Speed) string {
+	return fmt.Sprintf("%s %.*f %s", getWindDirectionSymbol(wind.Deg), unit.Decimals(), unit.Convert(wind.Speed), unit.Symbol())
+}
+
+// colorPrecipitation colors a precipitation amount and its probability like the current weather does
+func colorPrecipitation(text string) string {
+	amount, pop, found := strings.Cut(text, " | ")
+	if !found {
+		return color.BlueString(text)
+	}
+	return color.BlueString(amount) + " | " + color.CyanString(pop)
+}
+
+// truncateText shortens a text to the given number of runes, marking the cut with an ellipsis
+func truncateText(text string, width int) string {
+	if utf8.RuneCountInString(text) <= width {
+		return text
+	}
+	return string([]rune(text)[:width-1]) + "…"
 }
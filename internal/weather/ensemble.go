@@ -0,0 +1,247 @@
+package weather
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"sync"
+)
+
+// ProviderEnsemble names readings merged from several providers
+const ProviderEnsemble = "Ensemble"
+
+// ensembleOutlierMinDelta is the smallest temperature deviation from the median, in °C,
+// for which a provider is flagged as an outlier
+const ensembleOutlierMinDelta = 2.0
+
+// EnsembleInfo describes how the providers merged into an ensemble reading agreed
+type EnsembleInfo struct {
+	Members []EnsembleMember
+	// TempSpread is the standard deviation of the member temperatures in °C
+	TempSpread float64
+}
+
+// EnsembleMember is the reading of a single provider taking part in an ensemble
+type EnsembleMember struct {
+	Provider string
+	Temp     float64
+	Outlier  bool
+}
+
+// ensembleProviders returns the providers to query in ensemble mode: the configured
+// providers list, or every registered provider that can be used with the configuration
+func ensembleProviders(config Config) []string {
+	if len(config.Providers) > 0 {
+		return config.Providers
+	}
+
+	names := make([]string, 0, len(registry))
+	for _, name := range ProviderNames() {
+		if provider, _ := LookupProvider(name); provider.Capabilities().RequiresAPIKey && config.ApiKey == "" {
+			continue
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+// FetchEnsemble queries every configured provider concurrently and merges their readings.
+// Providers failing are left out, an error is only returned when all of them fail.
+func FetchEnsemble(ctx context.Context, config Config) (*Weather, error) {
+	names := ensembleProviders(config)
+	readings := make([]*Weather, len(names))
+	errs := make([]error, len(names))
+
+	var wg sync.WaitGroup
+	for i, name := range names {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			readings[i], errs[i] = fetchWeatherFrom(ctx, name, config)
+			if errs[i] != nil {
+				Logger.InfoContext(ctx, "ensemble member failed", "provider", name, "error", errs[i])
+				errs[i] = fmt.Errorf("%s: %w", name, errs[i])
+				return
+			}
+			readings[i].Provider = name
+		}()
+	}
+	wg.Wait()
+
+	readings = slices.DeleteFunc(readings, func(w *Weather) bool { return w == nil })
+	if len(readings) == 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	merged := MergeWeather(readings)
+	Logger.InfoContext(ctx, "ensemble merged", "members", len(readings), "of", len(names))
+	return &merged, nil
+}
+
+// MergeWeather reconciles readings of several providers: the temperature and humidity are
+// medians, the wind is vector-averaged, the precipitation is the maximum reported and the
+// condition is the one most providers agree on. Optional quantities are the medians of
+// the providers reporting them.
+func MergeWeather(readings []*Weather) Weather {
+	temps := make([]float64, len(readings))
+	humidities := make([]float64, len(readings))
+	var windU, windV float64
+	merged := Weather{Name: readings[0].Name, Provider: ProviderEnsemble, FetchedAt: readings[0].FetchedAt}
+
+	for i, reading := range readings {
+		temps[i] = reading.Main.Temp
+		humidities[i] = float64(reading.Main.Humidity)
+
+		// Average the wind as vectors so that 350° and 10° give north rather than south
+		speed := reading.Wind.Speed
+		direction := float64(reading.Wind.Deg) * math.Pi / 180
+		windU += speed * math.Sin(direction)
+		windV += speed * math.Cos(direction)
+
+		merged.Rain.OneHour = max(merged.Rain.OneHour, reading.Rain.OneHour)
+		merged.Pop = max(merged.Pop, reading.Pop)
+		if reading.ObservedAt.After(merged.ObservedAt) {
+			merged.ObservedAt = reading.ObservedAt
+		}
+
+		// Sunrise, sunset and timezone only depend on the location
+		if merged.Sunrise == nil {
+			merged.Sunrise, merged.Sunset = reading.Sunrise, reading.Sunset
+		}
+		if merged.Timezone == nil {
+			merged.Timezone = reading.Timezone
+		}
+
+		// The merged reading is as old as its oldest member
+		if reading.FetchedAt.Before(merged.FetchedAt) {
+			merged.FetchedAt = reading.FetchedAt
+		}
+	}
+
+	n := float64(len(readings))
+	windU, windV = windU/n, windV/n
+	merged.Wind.Speed = math.Hypot(windU, windV)
+	merged.Wind.Deg = (int(math.Round(math.Atan2(windU, windV)*180/math.Pi)) + 360) % 360
+
+	merged.Main.Temp = median(temps)
+	merged.Main.Humidity = int(math.Round(median(humidities)))
+	merged.Weather = majorityCondition(readings)
+
+	merged.Main.FeelsLike = medianOf(readings, func(w *Weather) *float64 { return w.Main.FeelsLike })
+	merged.Main.DewPoint = medianOf(readings, func(w *Weather) *float64 { return w.Main.DewPoint })
+	merged.Main.TempMin = medianOf(readings, func(w *Weather) *float64 { return w.Main.TempMin })
+	merged.Main.TempMax = medianOf(readings, func(w *Weather) *float64 { return w.Main.TempMax })
+	merged.Main.Pressure = medianOf(readings, func(w *Weather) *float64 { return w.Main.Pressure })
+	merged.Wind.Gust = medianOf(readings, func(w *Weather) *float64 { return w.Wind.Gust })
+	merged.Visibility = medianOf(readings, func(w *Weather) *float64 { return w.Visibility })
+	merged.UVIndex = medianOf(readings, func(w *Weather) *float64 { return w.UVIndex })
+	if cloudCover := medianOf(readings, func(w *Weather) *float64 {
+		if w.CloudCover == nil {
+			return nil
+		}
+		return ptr(float64(*w.CloudCover))
+	}); cloudCover != nil {
+		merged.CloudCover = ptr(int(math.Round(*cloudCover)))
+	}
+
+	info := &EnsembleInfo{
+		Members:    make([]EnsembleMember, len(readings)),
+		TempSpread: standardDeviation(temps),
+	}
+
+	// Flag members far from the median, scaled by the median absolute deviation so that a
+	// single disagreeing provider doesn't make the others look wrong. Two readings can't
+	// tell which one is off, so outliers need at least three.
+	deviations := make([]float64, len(temps))
+	for i, temp := range temps {
+		deviations[i] = math.Abs(temp - merged.Main.Temp)
+	}
+	threshold := max(ensembleOutlierMinDelta, 3*1.4826*median(deviations))
+	for i, reading := range readings {
+		info.Members[i] = EnsembleMember{
+			Provider: reading.Provider,
+			Temp:     temps[i],
+			Outlier:  len(readings) >= 3 && deviations[i] > threshold,
+		}
+	}
+	merged.Ensemble = info
+
+	return merged
+}
+
+// majorityCondition returns the conditions of the first reading whose main condition
+// is shared by the most readings
+func majorityCondition(readings []*Weather) []WeatherCondition {
+	counts := make(map[string]int, len(readings))
+	for _, reading := range readings {
+		if len(reading.Weather) > 0 {
+			counts[reading.Weather[0].Main]++
+		}
+	}
+
+	var best *Weather
+	for _, reading := range readings {
+		if len(reading.Weather) == 0 {
+			continue
+		}
+		if best == nil || counts[reading.Weather[0].Main] > counts[best.Weather[0].Main] {
+			best = reading
+		}
+	}
+
+	if best == nil {
+		return nil
+	}
+	return best.Weather
+}
+
+// medianOf returns the median of an optional quantity over the readings reporting it,
+// or nil when none does
+func medianOf(readings []*Weather, quantity func(*Weather) *float64) *float64 {
+	values := make([]float64, 0, len(readings))
+	for _, reading := range readings {
+		if v := quantity(reading); v != nil {
+			values = append(values, *v)
+		}
+	}
+
+	if len(values) == 0 {
+		return nil
+	}
+	return ptr(median(values))
+}
+
+// median returns the median of the values without reordering them
+func median(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+
+	sorted := slices.Sorted(slices.Values(values))
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
+
+// standardDeviation returns the standard deviation of the values around their mean
+func standardDeviation(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+
+	mean := 0.0
+	for _, v := range values {
+		mean += v
+	}
+	mean /= float64(len(values))
+
+	variance := 0.0
+	for _, v := range values {
+		variance += (v - mean) * (v - mean)
+	}
+	return math.Sqrt(variance / float64(len(values)))
+}
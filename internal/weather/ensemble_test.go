@@ -0,0 +1,92 @@
+package weather
+
+import (
+	"math"
+	"testing"
+)
+
+func TestMergeWeather(t *testing.T) {
+	reading := func(provider string, temp float64, humidity, direction int, condition int, pressure *float64) *Weather {
+		return &Weather{
+			Weather:  []WeatherCondition{WMOCondition(condition)},
+			Main:     Measurements{Temp: temp, Humidity: humidity, Pressure: pressure},
+			Wind:     Wind{Speed: 2, Deg: direction},
+			Provider: provider,
+		}
+	}
+
+	merged := MergeWeather([]*Weather{
+		reading("a", 10, 50, 350, 61, ptr(1010.0)),
+		reading("b", 11, 60, 10, 61, nil),
+		reading("c", 12, 70, 350, 0, ptr(1014.0)),
+		reading("d", 30, 80, 10, 61, nil),
+	})
+
+	if merged.Provider != ProviderEnsemble {
+		t.Errorf("got provider %q, want %q", merged.Provider, ProviderEnsemble)
+	}
+	if merged.Main.Temp != 11.5 || merged.Main.Humidity != 65 {
+		t.Errorf("got %v °C and %d%%, want the medians 11.5 °C and 65%%", merged.Main.Temp, merged.Main.Humidity)
+	}
+	if merged.Main.Pressure == nil || *merged.Main.Pressure != 1012 {
+		t.Errorf("got pressure %v, want the median of the reported ones, 1012", merged.Main.Pressure)
+	}
+	if merged.Wind.Deg != 0 || math.Abs(merged.Wind.Speed-2*math.Cos(10*math.Pi/180)) > 1e-9 {
+		t.Errorf("got wind %v m/s from %d°, want the vector average from the north", merged.Wind.Speed, merged.Wind.Deg)
+	}
+	if merged.Weather[0].Main != ConditionRain {
+		t.Errorf("got condition %q, want the majority %q", merged.Weather[0].Main, ConditionRain)
+	}
+
+	// The spread is the standard deviation around the mean of 15.75 °C
+	if want := math.Sqrt(272.75 / 4); math.Abs(merged.Ensemble.TempSpread-want) > 1e-9 {
+		t.Errorf("got spread %v, want %v", merged.Ensemble.TempSpread, want)
+	}
+	for _, member := range merged.Ensemble.Members {
+		if want := member.Provider == "d"; member.Outlier != want {
+			t.Errorf("member %s flagged as outlier: %v, want %v", member.Provider, member.Outlier, want)
+		}
+	}
+}
+
+func TestMergeWeatherTwoReadings(t *testing.T) {
+	merged := MergeWeather([]*Weather{
+		{Main: Measurements{Temp: 10}, Provider: "a"},
+		{Main: Measurements{Temp: 30}, Provider: "b"},
+	})
+
+	if merged.Ensemble.TempSpread != 10 {
+		t.Errorf("got spread %v, want 10", merged.Ensemble.TempSpread)
+	}
+	for _, member := range merged.Ensemble.Members {
+		if member.Outlier {
+			t.Errorf("member %s flagged as outlier, two readings can't tell which one is off", member.Provider)
+		}
+	}
+}
+
+func TestMedianAndStandardDeviation(t *testing.T) {
+	tests := []struct {
+		name       string
+		values     []float64
+		wantMedian float64
+		wantSD     float64
+	}{
+		{"empty", nil, 0, 0},
+		{"single", []float64{4}, 4, 0},
+		{"odd", []float64{3, 1, 2}, 2, math.Sqrt(2.0 / 3)},
+		{"even", []float64{4, 1, 3, 2}, 2.5, math.Sqrt(1.25)},
+		{"skewed", []float64{0, 0, 0, 8}, 0, math.Sqrt(12)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := median(tt.values); got != tt.wantMedian {
+				t.Errorf("got median %v, want %v", got, tt.wantMedian)
+			}
+			if got := standardDeviation(tt.values); math.Abs(got-tt.wantSD) > 1e-9 {
+				t.Errorf("got standard deviation %v, want %v", got, tt.wantSD)
+			}
+		})
+	}
+}
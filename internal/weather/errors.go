@@ -0,0 +1,109 @@
+package weather
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	// ErrUnsupportedQuery is returned for location queries that can't be parsed
+	ErrUnsupportedQuery = errors.New("unsupported query")
+	// ErrInvalidAPIKey is returned when a provider rejects the configured API key
+	ErrInvalidAPIKey = errors.New("invalid API key")
+	// ErrMissingAPIKey is returned when a provider requiring an API key has none configured
+	ErrMissingAPIKey = errors.New("missing API key")
+	// ErrLocationNotFound is returned when a location can't be resolved or isn't covered by a provider
+	ErrLocationNotFound = errors.New("location not found")
+	// ErrRateLimited is returned when a provider keeps refusing requests because too many were sent
+	ErrRateLimited = errors.New("rate limited")
+)
+
+// bodySnippetLength is how much of an error response body is kept for the error message
+const bodySnippetLength = 200
+
+// HTTPStatusError is returned when a provider answers with an unexpected status code.
+// It unwraps to ErrInvalidAPIKey, ErrLocationNotFound or ErrRateLimited when the status
+// has that meaning.
+type HTTPStatusError struct {
+	StatusCode int
+	// Body is the beginning of the response body, which usually explains the error
+	Body string
+}
+
+// newHTTPStatusError builds the error for a response, reading the start of its body
+func newHTTPStatusError(resp *http.Response) *HTTPStatusError {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 4*bodySnippetLength))
+	snippet := strings.Join(strings.Fields(strings.ToValidUTF8(string(body), "")), " ")
+	if utf8.RuneCountInString(snippet) > bodySnippetLength {
+		snippet = string([]rune(snippet)[:bodySnippetLength]) + "…"
+	}
+
+	return &HTTPStatusError{StatusCode: resp.StatusCode, Body: snippet}
+}
+
+func (e *HTTPStatusError) Error() string {
+	msg := fmt.Sprintf("API returned status %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+	if sentinel := e.Unwrap(); sentinel != nil {
+		msg = fmt.Sprintf("%v (status %d %s)", sentinel, e.StatusCode, http.StatusText(e.StatusCode))
+	}
+	if e.Body != "" {
+		msg += ": " + e.Body
+	}
+	return msg
+}
+
+// Unwrap returns the sentinel error matching the status code, if any
+func (e *HTTPStatusError) Unwrap() error {
+	switch e.StatusCode {
+	case http.StatusUnauthorized:
+		return ErrInvalidAPIKey
+	case http.StatusNotFound:
+		return ErrLocationNotFound
+	case http.StatusTooManyRequests:
+		return ErrRateLimited
+	default:
+		return nil
+	}
+}
+
+// NetworkError is returned when a provider couldn't be reached at all: DNS failures,
+// refused connections, timeouts, TLS errors, ...
+type NetworkError struct {
+	Err error
+}
+
+// newNetworkError wraps an error returned by an HTTP client, redacting the API key
+// from the URL it mentions
+func newNetworkError(err error) *NetworkError {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		urlErr.URL = redactURLString(urlErr.URL)
+	}
+	return &NetworkError{Err: err}
+}
+
+func (e *NetworkError) Error() string {
+	return "network error: " + e.Err.Error()
+}
+
+func (e *NetworkError) Unwrap() error {
+	return e.Err
+}
+
+// DecodeError is returned when a provider answers with a response that can't be decoded
+type DecodeError struct {
+	Err error
+}
+
+func (e *DecodeError) Error() string {
+	return "invalid response: " + e.Err.Error()
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
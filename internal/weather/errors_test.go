@@ -0,0 +1,23 @@
+package weather
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestNewNetworkError(t *testing.T) {
+	// Nothing listens on port 1, so the request fails before reaching a server
+	_, err := http.Get("http://127.0.0.1:1/weather?q=Paris&appid=secret")
+	if err == nil {
+		t.Fatal("request succeeded without a server")
+	}
+
+	networkErr := newNetworkError(err)
+	if !IsNetworkError(networkErr) {
+		t.Errorf("got %T, want a network error", networkErr)
+	}
+	if msg := networkErr.Error(); strings.Contains(msg, "secret") || !strings.Contains(msg, "appid=REDACTED") {
+		t.Errorf("the API key isn't redacted from %q", msg)
+	}
+}
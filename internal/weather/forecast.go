@@ -0,0 +1,250 @@
+package weather
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"time"
+)
+
+// ForecastProvider is implemented by providers able to forecast the coming hours,
+// on top of reporting the current conditions
+type ForecastProvider interface {
+	// FetchForecast fetches the forecast for a resolved location, from the current hour on
+	FetchForecast(ctx context.Context, config Config, location GeoResult, request ForecastRequest) (*Forecast, error)
+}
+
+// ForecastRequest tells how far ahead to forecast, either by hours or by days
+type ForecastRequest struct {
+	// Hours is the number of hours to forecast
+	Hours int
+	// Days is the number of days to forecast from today, in which case the hourly
+	// forecast covers them as well
+	Days int
+}
+
+// Limits of the hourly forecast, in hours, and of the daily forecast, in days
+const (
+	DefaultForecastHours = 24
+	MaxForecastHours     = 384
+	MaxForecastDays      = 16
+)
+
+// DayParts are the parts a day is split into in the daily forecast, by their starting and
+// ending hours. The night is the one following the evening.
+var DayParts = [...]struct {
+	Name     string
+	From, To int
+}{
+	{"Morning", 6, 12},
+	{"Noon", 12, 18},
+	{"Evening", 18, 24},
+	{"Night", 24, 30},
+}
+
+// Location returns the timezone of the forecast, the local one when the provider doesn't report it
+func (f *Forecast) Location() *time.Location {
+	if f.Timezone == nil {
+		return time.Local
+	}
+	return f.Timezone.Location()
+}
+
+// DayPart summarizes the hourly forecast over one of the DayParts of a day of the daily forecast
+func (f *Forecast) DayPart(day ForecastPeriod, part int) (ForecastPeriod, bool) {
+	midnight := day.Start.In(f.Location())
+	start := time.Date(midnight.Year(), midnight.Month(), midnight.Day(), DayParts[part].From, 0, 0, 0, midnight.Location())
+	end := time.Date(midnight.Year(), midnight.Month(), midnight.Day(), DayParts[part].To, 0, 0, 0, midnight.Location())
+	return summarizeSteps(f.Hourly, start, end)
+}
+
+// FetchForecast fetches the forecast of the configured location from the first provider
+// of the chain able to forecast, falling through to the next one whenever one fails
+func FetchForecast(ctx context.Context, config Config, request ForecastRequest) (*Forecast, error) {
+	if config.Offline {
+		return nil, errors.New("forecasts aren't available offline")
+	}
+
+	chain := config.ProviderChain()
+	errs := make([]error, 0, len(chain))
+
+	for _, name := range chain {
+		forecast, err := fetchForecastFrom(ctx, name, config, request)
+		if err == nil {
+			Logger.InfoContext(ctx, "provider chosen", "provider", name)
+			forecast.Provider = name
+			return forecast, nil
+		}
+		Logger.InfoContext(ctx, "provider failed", "provider", name, "error", err)
+
+		if len(chain) == 1 {
+			return nil, err
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", name, err))
+	}
+
+	return nil, errors.Join(errs...)
+}
+
+// fetchForecastFrom resolves the configured city and fetches its forecast using a single provider
+func fetchForecastFrom(ctx context.Context, name string, config Config, request ForecastRequest) (*Forecast, error) {
+	provider, ok := LookupProvider(name)
+	if !ok {
+		return nil, fmt.Errorf("unknown provider %q", name)
+	}
+	forecaster, ok := provider.(ForecastProvider)
+	if !ok {
+		return nil, fmt.Errorf("%s doesn't provide forecasts", name)
+	}
+	if provider.Capabilities().RequiresAPIKey && config.ApiKey == "" {
+		return nil, fmt.Errorf("%w for %s", ErrMissingAPIKey, name)
+	}
+
+	location, err := resolveLocation(ctx, provider, config)
+	if err != nil {
+		return nil, err
+	}
+
+	// Serve a recent enough forecast without touching the network
+	key := fmt.Sprintf("%s|%.4f,%.4f|%+v", name, location.Latitude, location.Longitude, request)
+	maxAge := config.CacheMaxAge.Duration
+	if maxAge > 0 {
+		if cached, _, ok := readCache[Forecast]("forecast", key); ok && time.Since(cached.FetchedAt) < maxAge {
+			Logger.InfoContext(ctx, "forecast cache hit",
+				"provider", name, "age", time.Since(cached.FetchedAt).Round(time.Second))
+			trimForecast(&cached, time.Now(), request)
+			return &cached, nil
+		}
+		Logger.DebugContext(ctx, "forecast cache miss", "provider", name, "key", key)
+	}
+
+	forecast, err := forecaster.FetchForecast(ctx, config, *location, request)
+	if err != nil {
+		return nil, err
+	}
+	forecast.FetchedAt = time.Now()
+	if len(forecast.Hourly) == 0 {
+		return nil, fmt.Errorf("no forecast data returned for %s", location.Name)
+	}
+
+	// Providers without a daily forecast have it summarized from their hourly one
+	if request.Days > 0 && len(forecast.Daily) == 0 {
+		forecast.Daily = summarizeDays(forecast.Hourly, forecast.Location())
+	}
+
+	if maxAge > 0 {
+		storeCache(ctx, "forecast", key, *forecast)
+	}
+
+	trimForecast(forecast, forecast.FetchedAt, request)
+	return forecast, nil
+}
+
+// trimForecast drops what is already past from the forecast, and what goes beyond the request
+func trimForecast(forecast *Forecast, now time.Time, request ForecastRequest) {
+	if request.Hours > 0 {
+		forecast.Hourly = trimForecastSteps(forecast.Hourly, now, request.Hours)
+	}
+	if request.Days > 0 {
+		forecast.Daily = slices.DeleteFunc(forecast.Daily, func(day ForecastPeriod) bool {
+			return !day.Start.Add(day.Duration).After(now)
+		})
+		forecast.Daily = forecast.Daily[:min(len(forecast.Daily), request.Days)]
+	}
+}
+
+// trimForecastSteps keeps the steps covering the given number of hours, starting with the
+// one in progress
+func trimForecastSteps(steps []ForecastStep, now time.Time, hours int) []ForecastStep {
+	trimmed := make([]ForecastStep, 0, len(steps))
+	var end time.Time
+	for _, step := range steps {
+		if !step.Time.Add(step.Duration).After(now) {
+			continue
+		}
+		if len(trimmed) == 0 {
+			end = step.Time.Add(time.Duration(hours) * time.Hour)
+		}
+		if !step.Time.Before(end) {
+			break
+		}
+		trimmed = append(trimmed, step)
+	}
+	return trimmed
+}
+
+// summarizeDays summarizes the steps over each day they cover, from midnight to midnight
+// in the given timezone
+func summarizeDays(steps []ForecastStep, location *time.Location) []ForecastPeriod {
+	if len(steps) == 0 {
+		return nil
+	}
+
+	first := steps[0].Time.In(location)
+	last := steps[len(steps)-1].Time.In(location)
+	days := make([]ForecastPeriod, 0, int(last.Sub(first).Hours()/24)+2)
+	for day := time.Date(first.Year(), first.Month(), first.Day(), 0, 0, 0, 0, location); !day.After(last); day = day.AddDate(0, 0, 1) {
+		if summary, ok := summarizeSteps(steps, day, day.AddDate(0, 0, 1)); ok {
+			days = append(days, summary)
+		}
+	}
+	return days
+}
+
+// summarizeSteps summarizes the steps overlapping the period from start to end. It reports
+// false when none does.
+func summarizeSteps(steps []ForecastStep, start, end time.Time) (ForecastPeriod, bool) {
+	period := ForecastPeriod{Start: start, Duration: end.Sub(start)}
+	counts := make(map[string]int)
+	var windU, windV float64
+	var dominant *ForecastStep
+
+	for i, step := range steps {
+		stepStart, stepEnd := step.Time, step.Time.Add(step.Duration)
+		if !stepEnd.After(start) || !stepStart.Before(end) {
+			continue
+		}
+
+		if dominant == nil {
+			period.TempMin, period.TempMax = step.Temp, step.Temp
+		}
+		period.TempMin = min(period.TempMin, step.Temp)
+		period.TempMax = max(period.TempMax, step.Temp)
+
+		// Only count the share of the precipitation falling within the period
+		if stepStart.Before(start) {
+			stepStart = start
+		}
+		if stepEnd.After(end) {
+			stepEnd = end
+		}
+		period.Precipitation += step.Precipitation * float64(stepEnd.Sub(stepStart)) / float64(step.Duration)
+
+		if step.Pop != nil && (period.Pop == nil || *step.Pop > *period.Pop) {
+			period.Pop = step.Pop
+		}
+
+		// The dominant direction is the average of the wind vectors, which favors strong winds
+		direction := float64(step.Wind.Deg) * math.Pi / 180
+		windU += step.Wind.Speed * math.Sin(direction)
+		windV += step.Wind.Speed * math.Cos(direction)
+		period.Wind.Speed = max(period.Wind.Speed, step.Wind.Speed)
+		if step.Wind.Gust != nil && (period.Wind.Gust == nil || *step.Wind.Gust > *period.Wind.Gust) {
+			period.Wind.Gust = step.Wind.Gust
+		}
+
+		counts[step.Condition.Main]++
+		if dominant == nil || counts[step.Condition.Main] > counts[dominant.Condition.Main] {
+			dominant = &steps[i]
+		}
+	}
+
+	if dominant == nil {
+		return ForecastPeriod{}, false
+	}
+	period.Condition = dominant.Condition
+	period.Wind.Deg = (int(math.Round(math.Atan2(windU, windV)*180/math.Pi)) + 360) % 360
+	return period, true
+}
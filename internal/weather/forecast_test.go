@@ -0,0 +1,198 @@
+package weather
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// hourlySteps returns n steps of the given length from start, warming by a degree each
+func hourlySteps(start time.Time, n int, duration time.Duration) []ForecastStep {
+	steps := make([]ForecastStep, n)
+	for i := range steps {
+		steps[i] = ForecastStep{
+			Time:      start.Add(time.Duration(i) * duration),
+			Duration:  duration,
+			Condition: WMOCondition(0),
+			Temp:      float64(i),
+		}
+	}
+	return steps
+}
+
+func TestTrimForecastHours(t *testing.T) {
+	start := time.Date(2026, 3, 14, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		steps     []ForecastStep
+		now       time.Time
+		hours     int
+		wantFirst time.Time
+		wantSteps int
+	}{
+		{"hourly steps", hourlySteps(start, 48, time.Hour), start.Add(90 * time.Minute), 3, start.Add(time.Hour), 3},
+		{"step boundary", hourlySteps(start, 48, time.Hour), start.Add(2 * time.Hour), 24, start.Add(2 * time.Hour), 24},
+		{"beyond the forecast", hourlySteps(start, 10, time.Hour), start, 24, start, 10},
+		{"three hour steps", hourlySteps(start, 8, 3*time.Hour), start.Add(90 * time.Minute), 4, start, 2},
+		{"all past", hourlySteps(start, 5, time.Hour), start.Add(6 * time.Hour), 3, time.Time{}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			forecast := Forecast{Hourly: tt.steps}
+			trimForecast(&forecast, tt.now, ForecastRequest{Hours: tt.hours})
+
+			if len(forecast.Hourly) != tt.wantSteps {
+				t.Fatalf("got %d steps, want %d", len(forecast.Hourly), tt.wantSteps)
+			}
+			if tt.wantSteps > 0 && !forecast.Hourly[0].Time.Equal(tt.wantFirst) {
+				t.Errorf("first step at %v, want %v", forecast.Hourly[0].Time, tt.wantFirst)
+			}
+		})
+	}
+}
+
+func TestTrimForecastDays(t *testing.T) {
+	midnight := time.Date(2026, 3, 14, 0, 0, 0, 0, time.UTC)
+	days := make([]ForecastPeriod, 7)
+	for i := range days {
+		days[i] = ForecastPeriod{Start: midnight.AddDate(0, 0, i), Duration: 24 * time.Hour}
+	}
+
+	tests := []struct {
+		name      string
+		now       time.Time
+		days      int
+		wantFirst time.Time
+		wantDays  int
+	}{
+		{"today", midnight.Add(10 * time.Hour), 3, midnight, 3},
+		{"past days", midnight.AddDate(0, 0, 2).Add(time.Hour), 3, midnight.AddDate(0, 0, 2), 3},
+		{"beyond the forecast", midnight.AddDate(0, 0, 5), 3, midnight.AddDate(0, 0, 5), 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			forecast := Forecast{Daily: append([]ForecastPeriod(nil), days...)}
+			trimForecast(&forecast, tt.now, ForecastRequest{Days: tt.days})
+
+			if len(forecast.Daily) != tt.wantDays {
+				t.Fatalf("got %d days, want %d", len(forecast.Daily), tt.wantDays)
+			}
+			if !forecast.Daily[0].Start.Equal(tt.wantFirst) {
+				t.Errorf("first day starts at %v, want %v", forecast.Daily[0].Start, tt.wantFirst)
+			}
+		})
+	}
+}
+
+func TestSummarizeDays(t *testing.T) {
+	location := time.FixedZone("UTC+2", 2*60*60)
+	// From 20:00 local time on the 14th to 03:00 on the 16th
+	start := time.Date(2026, 3, 14, 20, 0, 0, 0, location)
+	steps := hourlySteps(start, 32, time.Hour)
+	for i := range steps {
+		steps[i].Precipitation = 0.5
+		steps[i].Pop = ptr(float64(i) / 100)
+		steps[i].Wind = Wind{Speed: 2, Deg: 90}
+	}
+	steps[10].Condition = WMOCondition(61)
+	steps[11].Condition = WMOCondition(61)
+	steps[12].Wind.Gust = ptr(12.0)
+
+	days := summarizeDays(steps, location)
+	if len(days) != 3 {
+		t.Fatalf("got %d days, want 3", len(days))
+	}
+
+	tests := []struct {
+		name                   string
+		day                    ForecastPeriod
+		wantStart              time.Time
+		wantMin, wantMax       float64
+		wantPrecipitation      float64
+		wantPop                float64
+		wantCondition          Condition
+		wantGust               *float64
+		wantDirection, wantFor int
+	}{
+		{"first evening", days[0], time.Date(2026, 3, 14, 0, 0, 0, 0, location), 0, 3, 2, 0.03, ConditionClear, nil, 90, 4},
+		{"full day", days[1], time.Date(2026, 3, 15, 0, 0, 0, 0, location), 4, 27, 12, 0.27, ConditionClear, ptr(12.0), 90, 24},
+		{"last night", days[2], time.Date(2026, 3, 16, 0, 0, 0, 0, location), 28, 31, 2, 0.31, ConditionClear, nil, 90, 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			day := tt.day
+			if !day.Start.Equal(tt.wantStart) || day.Duration != 24*time.Hour {
+				t.Errorf("got day from %v for %v, want from %v for 24h", day.Start, day.Duration, tt.wantStart)
+			}
+			if day.TempMin != tt.wantMin || day.TempMax != tt.wantMax {
+				t.Errorf("got %v to %v, want %v to %v", day.TempMin, day.TempMax, tt.wantMin, tt.wantMax)
+			}
+			if math.Abs(day.Precipitation-tt.wantPrecipitation) > 1e-9 {
+				t.Errorf("got %v mm, want %v mm", day.Precipitation, tt.wantPrecipitation)
+			}
+			if day.Pop == nil || math.Abs(*day.Pop-tt.wantPop) > 1e-9 {
+				t.Errorf("got probability %v, want %v", day.Pop, tt.wantPop)
+			}
+			if day.Condition.Main != tt.wantCondition {
+				t.Errorf("got condition %q, want %q", day.Condition.Main, tt.wantCondition)
+			}
+			if (day.Wind.Gust == nil) != (tt.wantGust == nil) || (tt.wantGust != nil && *day.Wind.Gust != *tt.wantGust) {
+				t.Errorf("got gust %v, want %v", day.Wind.Gust, tt.wantGust)
+			}
+			if day.Wind.Deg != tt.wantDirection || day.Wind.Speed != 2 {
+				t.Errorf("got wind %v m/s from %d°, want 2 m/s from %d°", day.Wind.Speed, day.Wind.Deg, tt.wantDirection)
+			}
+		})
+	}
+}
+
+func TestSummarizeSteps(t *testing.T) {
+	start := time.Date(2026, 3, 14, 0, 0, 0, 0, time.UTC)
+	steps := []ForecastStep{
+		// Half of this step falls within the period
+		{Time: start.Add(-3 * time.Hour), Duration: 6 * time.Hour, Condition: WMOCondition(61), Temp: 4, Precipitation: 6,
+			Wind: Wind{Speed: 4, Deg: 0}},
+		{Time: start.Add(3 * time.Hour), Duration: 6 * time.Hour, Condition: WMOCondition(61), Temp: 6, Precipitation: 3,
+			Wind: Wind{Speed: 4, Deg: 90}},
+		{Time: start.Add(9 * time.Hour), Duration: 6 * time.Hour, Condition: WMOCondition(0), Temp: 9},
+	}
+
+	tests := []struct {
+		name              string
+		from, to          time.Time
+		wantOK            bool
+		wantPrecipitation float64
+		wantCondition     Condition
+		wantDirection     int
+	}{
+		{"overlapping steps", start, start.Add(9 * time.Hour), true, 6, ConditionRain, 45},
+		{"majority", start, start.Add(24 * time.Hour), true, 6, ConditionRain, 45},
+		{"single step", start.Add(9 * time.Hour), start.Add(12 * time.Hour), true, 0, ConditionClear, 0},
+		{"no step", start.Add(48 * time.Hour), start.Add(54 * time.Hour), false, 0, "", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			period, ok := summarizeSteps(steps, tt.from, tt.to)
+			if ok != tt.wantOK {
+				t.Fatalf("got ok %v, want %v", ok, tt.wantOK)
+			}
+			if !ok {
+				return
+			}
+			if math.Abs(period.Precipitation-tt.wantPrecipitation) > 1e-9 {
+				t.Errorf("got %v mm, want %v mm", period.Precipitation, tt.wantPrecipitation)
+			}
+			if period.Condition.Main != tt.wantCondition {
+				t.Errorf("got condition %q, want %q", period.Condition.Main, tt.wantCondition)
+			}
+			if period.Wind.Deg != tt.wantDirection {
+				t.Errorf("got wind from %d°, want %d°", period.Wind.Deg, tt.wantDirection)
+			}
+		})
+	}
+}
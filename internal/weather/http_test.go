@@ -0,0 +1,190 @@
+package weather
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestBypassesProxy(t *testing.T) {
+	tests := []struct {
+		host, noProxy string
+		want          bool
+	}{
+		{"api.open-meteo.com", "", false},
+		{"api.open-meteo.com", "*", true},
+		{"api.open-meteo.com", "api.open-meteo.com", true},
+		{"API.Open-Meteo.com", "api.open-meteo.com", true},
+		{"api.open-meteo.com", "open-meteo.com", true},
+		{"api.open-meteo.com", ".open-meteo.com", true},
+		{"open-meteo.com", ".open-meteo.com", true},
+		{"notopen-meteo.com", "open-meteo.com", false},
+		{"api.open-meteo.com", "localhost, open-meteo.com:443", true},
+		{"10.1.2.3", "10.0.0.0/8", true},
+		{"192.168.1.1", "10.0.0.0/8", false},
+		{"127.0.0.1", "localhost,127.0.0.1", true},
+		{"api.met.no", " , ,example.com", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.host+" "+tt.noProxy, func(t *testing.T) {
+			if got := bypassesProxy(tt.host, tt.noProxy); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2026, 3, 14, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		value  string
+		want   time.Duration
+		wantOK bool
+	}{
+		{"empty", "", 0, false},
+		{"seconds", "120", 2 * time.Minute, true},
+		{"zero", "0", 0, true},
+		{"negative", "-5", 0, false},
+		{"date", "Sat, 14 Mar 2026 12:00:30 GMT", 30 * time.Second, true},
+		{"past date", "Sat, 14 Mar 2026 11:00:00 GMT", 0, true},
+		{"garbage", "soon", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseRetryAfter(tt.value, now)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("got %v, %v, want %v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		err    error
+		want   bool
+	}{
+		{"ok", http.StatusOK, nil, false},
+		{"not found", http.StatusNotFound, nil, false},
+		{"rate limited", http.StatusTooManyRequests, nil, true},
+		{"unavailable", http.StatusServiceUnavailable, nil, true},
+		{"internal error", http.StatusInternalServerError, nil, false},
+		{"refused", 0, &net.OpError{Op: "dial", Err: syscall.ECONNREFUSED}, true},
+		{"closed connection", 0, fmt.Errorf("reading response: %w", io.EOF), true},
+		{"temporary DNS failure", 0, &net.DNSError{Err: "server misbehaving", IsTemporary: true}, true},
+		{"unknown host", 0, &net.DNSError{Err: "no such host", IsNotFound: true}, false},
+		{"untrusted certificate", 0, &net.OpError{Op: "remote error", Err: x509.UnknownAuthorityError{}}, false},
+		{"canceled", 0, fmt.Errorf("request: %w", context.Canceled), false},
+		{"other", 0, errors.New("unsupported protocol scheme"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var resp *http.Response
+			if tt.err == nil {
+				resp = &http.Response{StatusCode: tt.status}
+			}
+			if got := isRetryable(resp, tt.err); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryTransport(t *testing.T) {
+	tests := []struct {
+		name string
+		// failures is the number of requests answered with the status before succeeding
+		failures   int
+		status     int
+		retryAfter string
+		retries    int
+		wantStatus int
+		wantCalls  int32
+	}{
+		{"success", 0, 0, "", 2, http.StatusOK, 1},
+		{"retried", 2, http.StatusServiceUnavailable, "0", 2, http.StatusOK, 3},
+		{"retries exhausted", 3, http.StatusTooManyRequests, "0", 2, http.StatusTooManyRequests, 3},
+		{"not retryable", 1, http.StatusNotFound, "0", 2, http.StatusNotFound, 1},
+		{"retries disabled", 1, http.StatusBadGateway, "0", 0, http.StatusBadGateway, 1},
+		{"Retry-After too long", 1, http.StatusTooManyRequests, "3600", 2, http.StatusTooManyRequests, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if int(calls.Add(1)) <= tt.failures {
+					w.Header().Set("Retry-After", tt.retryAfter)
+					w.WriteHeader(tt.status)
+					return
+				}
+				_, _ = io.WriteString(w, "{}")
+			}))
+			defer server.Close()
+
+			client := &http.Client{Transport: &retryTransport{base: http.DefaultTransport, retries: tt.retries}}
+			resp, err := client.Get(server.URL)
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			_ = resp.Body.Close()
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("got status %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if got := calls.Load(); got != tt.wantCalls {
+				t.Errorf("got %d requests, want %d", got, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestRetryTransportCanceled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "20")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := &http.Client{Transport: &retryTransport{base: http.DefaultTransport, retries: 3}}
+	start := time.Now()
+	if _, err := client.Do(req); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got error %v, want the context deadline", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("waiting for the retry wasn't interrupted, took %v", elapsed)
+	}
+}
+
+func TestClientInvalidSettings(t *testing.T) {
+	config := DefaultConfig()
+	config.CAFile = "/nonexistent/ca.pem"
+
+	_, err := config.Client().Get("http://127.0.0.1:1")
+	if err == nil {
+		t.Fatal("request succeeded with an unreadable CA file")
+	}
+}
@@ -1,5 +1,8 @@
 package weather
 
+// iconWidth is the visible width of every icon line
+const iconWidth = 13
+
 var (
 	// icon base monochrome icons with consistent 7-line height (top/bottom padding)
 	icon = map[string][]string{
@@ -199,6 +202,22 @@ var (
 	}
 )
 
+// iconGlyph holds a glyph per icon for one-line views like the hourly forecast. They avoid
+// emoji, whose width varies between terminals, and are padded to two columns.
+var iconGlyph = map[string]string{
+	ConditionUnknown:      "? ",
+	ConditionSunny:        "☀ ",
+	ConditionPartlyCloudy: "☀☁",
+	ConditionCloudy:       "☁ ",
+	ConditionVeryCloudy:   "☁☁",
+	ConditionLightShowers: "☂ ",
+	ConditionHeavyShowers: "☂☂",
+	ConditionLightSnow:    "❄ ",
+	ConditionHeavySnow:    "❄❄",
+	ConditionThunderstorm: "↯ ",
+	ConditionFog:          "≋ ",
+}
+
 // getIcon returns the ASCII art for a given weather condition with proper spacing
 func getIcon(name string, useColors bool) []string {
 	if !useColors {
@@ -213,6 +232,24 @@ func getIcon(name string, useColors bool) []string {
 
 // getWeatherIcon determines which icon to use based on weather condition
 func getWeatherIcon(weatherMain string, weatherID int, useColors bool) []string {
+	return getIcon(weatherIconName(weatherMain, weatherID), useColors)
+}
+
+// getSmallWeatherIcon returns the icon of a weather condition without its top and bottom
+// padding, for views packing several icons like the daily forecast
+func getSmallWeatherIcon(weatherMain string, weatherID int, useColors bool) []string {
+	lines := getWeatherIcon(weatherMain, weatherID, useColors)
+	return lines[1 : len(lines)-1]
+}
+
+// getWeatherGlyph returns the glyph of a weather condition, two columns wide
+func getWeatherGlyph(weatherMain string, weatherID int) string {
+	return iconGlyph[weatherIconName(weatherMain, weatherID)]
+}
+
+// weatherIconName picks the icon of a condition from its OpenWeatherMap ID, or from its
+// main condition for IDs without a dedicated icon
+func weatherIconName(weatherMain string, weatherID int) string {
 	iconMap := map[int]string{
 		// Thunderstorm
 		200: ConditionThunderstorm,
@@ -306,5 +343,5 @@ func getWeatherIcon(weatherMain string, weatherID int, useColors bool) []string
 		}
 	}
 
-	return getIcon(iconName, useColors)
+	return iconName
 }
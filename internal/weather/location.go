@@ -0,0 +1,128 @@
+package weather
+
+import (
+	"fmt"
+	"strings"
+)
+
+// LocationQuery is a city query split into its parts, such as "Springfield, IL, US"
+type LocationQuery struct {
+	Name string
+	// Admin1 is the first-level administrative region, like a state or province
+	Admin1 string
+	// Country is a country name or ISO 3166-1 alpha-2 code
+	Country string
+	// Qualifier is the second part of a two-part query, which may be either a region or a country
+	Qualifier string
+}
+
+// countryAliases maps common country abbreviations that aren't ISO codes
+var countryAliases = map[string]string{
+	"UK":  "GB",
+	"USA": "US",
+	"UAE": "AE",
+}
+
+// regionAbbreviations maps postal abbreviations of US states, Canadian provinces and
+// Australian states to the region names returned by geocoders
+var regionAbbreviations = map[string]string{
+	"AL": "Alabama", "AK": "Alaska", "AZ": "Arizona", "AR": "Arkansas", "CA": "California",
+	"CO": "Colorado", "CT": "Connecticut", "DE": "Delaware", "DC": "District of Columbia",
+	"FL": "Florida", "GA": "Georgia", "HI": "Hawaii", "ID": "Idaho", "IL": "Illinois",
+	"IN": "Indiana", "IA": "Iowa", "KS": "Kansas", "KY": "Kentucky", "LA": "Louisiana",
+	"ME": "Maine", "MD": "Maryland", "MA": "Massachusetts", "MI": "Michigan", "MN": "Minnesota",
+	"MS": "Mississippi", "MO": "Missouri", "MT": "Montana", "NE": "Nebraska", "NV": "Nevada",
+	"NH": "New Hampshire", "NJ": "New Jersey", "NM": "New Mexico", "NY": "New York",
+	"NC": "North Carolina", "ND": "North Dakota", "OH": "Ohio", "OK": "Oklahoma", "OR": "Oregon",
+	"PA": "Pennsylvania", "RI": "Rhode Island", "SC": "South Carolina", "SD": "South Dakota",
+	"TN": "Tennessee", "TX": "Texas", "UT": "Utah", "VT": "Vermont", "VA": "Virginia",
+	"WA": "Washington", "WV": "West Virginia", "WI": "Wisconsin", "WY": "Wyoming",
+
+	"AB": "Alberta", "BC": "British Columbia", "MB": "Manitoba", "NB": "New Brunswick",
+	"NL": "Newfoundland and Labrador", "NS": "Nova Scotia", "NT": "Northwest Territories",
+	"NU": "Nunavut", "ON": "Ontario", "PE": "Prince Edward Island", "QC": "Quebec",
+	"SK": "Saskatchewan", "YT": "Yukon",
+
+	"ACT": "Australian Capital Territory", "NSW": "New South Wales", "QLD": "Queensland",
+	"SA": "South Australia", "TAS": "Tasmania", "VIC": "Victoria",
+}
+
+// ParseLocationQuery splits a "name[, region][, country]" query into its parts.
+// Queries with more than three parts aren't supported.
+func ParseLocationQuery(query string) (LocationQuery, error) {
+	parts := strings.Split(query, ",")
+	for i := range parts {
+		parts[i] = strings.TrimSpace(parts[i])
+	}
+
+	switch len(parts) {
+	case 1:
+		return LocationQuery{Name: parts[0]}, nil
+	case 2:
+		return LocationQuery{Name: parts[0], Qualifier: parts[1]}, nil
+	case 3:
+		return LocationQuery{Name: parts[0], Admin1: parts[1], Country: parts[2]}, nil
+	default:
+		return LocationQuery{}, fmt.Errorf(
+			"%w: expected \"city\", \"city, region\" or \"city, region, country\", got %q", ErrUnsupportedQuery, query,
+		)
+	}
+}
+
+// CountryCode returns the ISO code of the country part when it's given as one
+func (q LocationQuery) CountryCode() string {
+	country := strings.ToUpper(q.Country)
+	if alias, ok := countryAliases[country]; ok {
+		return alias
+	}
+	if len(country) == 2 {
+		return country
+	}
+	return ""
+}
+
+// Matches reports whether a geocoding result agrees with the region and country of the query
+func (q LocationQuery) Matches(result GeoResult) bool {
+	if q.Qualifier != "" {
+		return matchesCountry(q.Qualifier, result) || matchesRegion(q.Qualifier, result)
+	}
+	if q.Admin1 != "" && !matchesRegion(q.Admin1, result) {
+		return false
+	}
+	if q.Country != "" && !matchesCountry(q.Country, result) {
+		return false
+	}
+	return true
+}
+
+func matchesCountry(country string, result GeoResult) bool {
+	if alias, ok := countryAliases[strings.ToUpper(country)]; ok {
+		country = alias
+	}
+	return strings.EqualFold(country, result.CountryCode) || strings.EqualFold(country, result.Country)
+}
+
+func matchesRegion(region string, result GeoResult) bool {
+	if result.Admin1 == "" {
+		return false
+	}
+	if name, ok := regionAbbreviations[strings.ToUpper(region)]; ok && strings.EqualFold(name, result.Admin1) {
+		return true
+	}
+	// Accept unambiguous prefixes like "Mass" for Massachusetts
+	return len(region) >= 3 && strings.HasPrefix(strings.ToLower(result.Admin1), strings.ToLower(region))
+}
+
+// DisplayName describes a geocoding result with its region and country, like "Springfield, Illinois, United States"
+func (r GeoResult) DisplayName() string {
+	parts := []string{r.Name}
+	if r.Admin1 != "" && r.Admin1 != r.Name {
+		parts = append(parts, r.Admin1)
+	}
+	if r.Country != "" {
+		parts = append(parts, r.Country)
+	} else if r.CountryCode != "" {
+		parts = append(parts, r.CountryCode)
+	}
+	return strings.Join(parts, ", ")
+}
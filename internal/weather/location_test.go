@@ -0,0 +1,112 @@
+package weather
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseLocationQuery(t *testing.T) {
+	tests := []struct {
+		query   string
+		want    LocationQuery
+		wantErr bool
+	}{
+		{"London", LocationQuery{Name: "London"}, false},
+		{"Paris, FR", LocationQuery{Name: "Paris", Qualifier: "FR"}, false},
+		{" Springfield ,IL, US ", LocationQuery{Name: "Springfield", Admin1: "IL", Country: "US"}, false},
+		{"a, b, c, d", LocationQuery{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			got, err := ParseLocationQuery(tt.query)
+			if tt.wantErr {
+				if !errors.Is(err, ErrUnsupportedQuery) {
+					t.Fatalf("got error %v, want %v", err, ErrUnsupportedQuery)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLocationQueryCountryCode(t *testing.T) {
+	tests := []struct {
+		country, want string
+	}{
+		{"us", "US"},
+		{"UK", "GB"},
+		{"usa", "US"},
+		{"Germany", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.country, func(t *testing.T) {
+			if got := (LocationQuery{Country: tt.country}).CountryCode(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLocationQueryMatches(t *testing.T) {
+	springfield := GeoResult{Name: "Springfield", Admin1: "Illinois", Country: "United States", CountryCode: "US"}
+
+	tests := []struct {
+		name  string
+		query string
+		want  bool
+	}{
+		{"name only", "Springfield", true},
+		{"state abbreviation", "Springfield, IL", true},
+		{"state prefix", "Springfield, Ill", true},
+		{"country code", "Springfield, us", true},
+		{"country alias", "Springfield, USA", true},
+		{"country name", "Springfield, United States", true},
+		{"other state", "Springfield, MA", false},
+		{"short prefix", "Springfield, Il", true},
+		{"region and country", "Springfield, Illinois, US", true},
+		{"region and other country", "Springfield, Illinois, CA", false},
+		{"other region", "Springfield, Ohio, US", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, err := ParseLocationQuery(tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := query.Matches(springfield); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGeoResultDisplayName(t *testing.T) {
+	tests := []struct {
+		name   string
+		result GeoResult
+		want   string
+	}{
+		{"full", GeoResult{Name: "Springfield", Admin1: "Illinois", Country: "United States"}, "Springfield, Illinois, United States"},
+		{"city state", GeoResult{Name: "Singapore", Admin1: "Singapore", Country: "Singapore"}, "Singapore, Singapore"},
+		{"country code only", GeoResult{Name: "Oslo", CountryCode: "NO"}, "Oslo, NO"},
+		{"name only", GeoResult{Name: "Somewhere"}, "Somewhere"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.result.DisplayName(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package weather
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// Logger receives the trace of what stormy does: requests, cache hits, resolved
+// locations and chosen providers. It discards everything unless main enables it.
+var Logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+// Log formats accepted by --log-format
+const (
+	LogFormatText = "text"
+	LogFormatJSON = "json"
+)
+
+// secretParams are the query parameters carrying credentials, redacted from logs and errors
+var secretParams = []string{"appid", "api_key", "apikey", "key", "token"}
+
+// NewLogger returns the logger requested by the --verbose, --debug and --log-format flags
+// or the STORMY_DEBUG environment variable, which enables debug logging when set to
+// anything but "0" or "false", as JSON when set to "json". It returns nil when logging
+// isn't enabled.
+func NewLogger(w io.Writer, flags Flags) *slog.Logger {
+	level := slog.LevelInfo
+	format := flags.LogFormat
+
+	switch env := strings.ToLower(os.Getenv("STORMY_DEBUG")); env {
+	case "", "0", "false":
+		if !flags.Verbose && !flags.Debug {
+			return nil
+		}
+		if flags.Debug {
+			level = slog.LevelDebug
+		}
+	default:
+		level = slog.LevelDebug
+		if env == LogFormatJSON && format == "" {
+			format = LogFormatJSON
+		}
+	}
+
+	options := &slog.HandlerOptions{Level: level}
+	if format == LogFormatJSON {
+		return slog.New(slog.NewJSONHandler(w, options))
+	}
+	return slog.New(slog.NewTextHandler(w, options))
+}
+
+// redactURL returns the URL with credentials in its query and user info replaced, so that
+// it can be shown without leaking the API key
+func redactURL(u *url.URL) string {
+	if u == nil {
+		return ""
+	}
+
+	redacted := *u
+	query := redacted.Query()
+	changed := false
+	for name := range query {
+		for _, secret := range secretParams {
+			if strings.EqualFold(name, secret) {
+				query.Set(name, "REDACTED")
+				changed = true
+			}
+		}
+	}
+	if changed {
+		redacted.RawQuery = query.Encode()
+	}
+
+	return redacted.Redacted()
+}
+
+// redactURLString is redactURL for URLs that haven't been parsed yet
+func redactURLString(s string) string {
+	u, err := url.Parse(s)
+	if err != nil {
+		return s
+	}
+	return redactURL(u)
+}
+
+// loggingTransport logs every request sent with its status code and latency
+type loggingTransport struct {
+	base http.RoundTripper
+}
+
+func (t *loggingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	latency := time.Since(start)
+
+	ctx := req.Context()
+	if err != nil {
+		Logger.InfoContext(ctx, "request failed",
+			"method", req.Method, "url", redactURL(req.URL), "latency", latency, "error", err)
+		return resp, err
+	}
+
+	Logger.InfoContext(ctx, "request",
+		"method", req.Method, "url", redactURL(req.URL), "status", resp.StatusCode, "latency", latency)
+	Logger.DebugContext(ctx, "response headers",
+		"url", redactURL(req.URL), "content_type", resp.Header.Get("Content-Type"),
+		"cache_control", resp.Header.Get("Cache-Control"), "expires", resp.Header.Get("Expires"))
+
+	return resp, nil
+}
@@ -0,0 +1,355 @@
+package weather
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const ProviderMetNorway = "MetNorway"
+
+// metNorwayEndpoint has no geocoder, locations are resolved with the Open-Meteo endpoints
+var metNorwayEndpoint = Endpoint{
+	BaseURL: "https://api.met.no/weatherapi/locationforecast/2.0",
+}
+
+func init() {
+	RegisterProvider(metNorwayProvider{})
+}
+
+type MetNorwayForecast struct {
+	Properties struct {
+		Meta struct {
+			UpdatedAt string `json:"updated_at"`
+		} `json:"meta"`
+		Timeseries []MetNorwayTimestep `json:"timeseries"`
+	} `json:"properties"`
+}
+
+type MetNorwayTimestep struct {
+	Time time.Time `json:"time"`
+	Data struct {
+		Instant struct {
+			Details struct {
+				AirPressureAtSeaLevel    *float64 `json:"air_pressure_at_sea_level"`
+				AirTemperature           float64  `json:"air_temperature"`
+				CloudAreaFraction        *float64 `json:"cloud_area_fraction"`
+				DewPointTemperature      *float64 `json:"dew_point_temperature"`
+				RelativeHumidity         float64  `json:"relative_humidity"`
+				UltravioletIndexClearSky *float64 `json:"ultraviolet_index_clear_sky"`
+				WindFromDirection        float64  `json:"wind_from_direction"`
+				WindSpeed                float64  `json:"wind_speed"`
+				WindSpeedOfGust          *float64 `json:"wind_speed_of_gust"`
+			} `json:"details"`
+		} `json:"instant"`
+		Next1Hours *MetNorwayPeriod `json:"next_1_hours"`
+		Next6Hours *MetNorwayPeriod `json:"next_6_hours"`
+	} `json:"data"`
+}
+
+type MetNorwayPeriod struct {
+	Summary struct {
+		SymbolCode string `json:"symbol_code"`
+	} `json:"summary"`
+	Details struct {
+		PrecipitationAmount        float64  `json:"precipitation_amount"`
+		ProbabilityOfPrecipitation *float64 `json:"probability_of_precipitation"`
+	} `json:"details"`
+}
+
+// metNorwayCachedForecast keeps a forecast together with the validators MET Norway
+// requires clients to honor before asking for the same location again
+type metNorwayCachedForecast struct {
+	Expires      time.Time         `json:"expires"`
+	LastModified string            `json:"last_modified"`
+	Forecast     MetNorwayForecast `json:"forecast"`
+}
+
+var metNorwaySymbols = map[string]WeatherCondition{
+	"clearsky":     {800, ConditionClear, "clear sky"},
+	"fair":         {801, ConditionClouds, "fair"},
+	"partlycloudy": {802, ConditionClouds, "partly cloudy"},
+	"cloudy":       {804, ConditionClouds, "cloudy"},
+	"fog":          {741, ConditionFog, "fog"},
+
+	"lightrain":        {500, ConditionRain, "light rain"},
+	"rain":             {501, ConditionRain, "rain"},
+	"heavyrain":        {502, ConditionRain, "heavy rain"},
+	"lightrainshowers": {520, ConditionRain, "light rain showers"},
+	"rainshowers":      {521, ConditionRain, "rain showers"},
+	"heavyrainshowers": {522, ConditionRain, "heavy rain showers"},
+
+	"lightsleet":        {612, ConditionSnow, "light sleet"},
+	"sleet":             {611, ConditionSnow, "sleet"},
+	"heavysleet":        {613, ConditionSnow, "heavy sleet"},
+	"lightsleetshowers": {612, ConditionSnow, "light sleet showers"},
+	"sleetshowers":      {613, ConditionSnow, "sleet showers"},
+	"heavysleetshowers": {613, ConditionSnow, "heavy sleet showers"},
+
+	"lightsnow":        {600, ConditionSnow, "light snow"},
+	"snow":             {601, ConditionSnow, "snow"},
+	"heavysnow":        {602, ConditionSnow, "heavy snow"},
+	"lightsnowshowers": {620, ConditionSnow, "light snow showers"},
+	"snowshowers":      {621, ConditionSnow, "snow showers"},
+	"heavysnowshowers": {622, ConditionSnow, "heavy snow showers"},
+
+	"lightrainandthunder":          {200, ConditionThunderstorm, "light rain and thunder"},
+	"rainandthunder":               {201, ConditionThunderstorm, "rain and thunder"},
+	"heavyrainandthunder":          {202, ConditionThunderstorm, "heavy rain and thunder"},
+	"lightrainshowersandthunder":   {200, ConditionThunderstorm, "light rain showers and thunder"},
+	"rainshowersandthunder":        {201, ConditionThunderstorm, "rain showers and thunder"},
+	"heavyrainshowersandthunder":   {202, ConditionThunderstorm, "heavy rain showers and thunder"},
+	"lightsleetandthunder":         {200, ConditionThunderstorm, "light sleet and thunder"},
+	"sleetandthunder":              {201, ConditionThunderstorm, "sleet and thunder"},
+	"heavysleetandthunder":         {202, ConditionThunderstorm, "heavy sleet and thunder"},
+	"lightssleetshowersandthunder": {200, ConditionThunderstorm, "light sleet showers and thunder"},
+	"sleetshowersandthunder":       {201, ConditionThunderstorm, "sleet showers and thunder"},
+	"heavysleetshowersandthunder":  {202, ConditionThunderstorm, "heavy sleet showers and thunder"},
+	"lightsnowandthunder":          {200, ConditionThunderstorm, "light snow and thunder"},
+	"snowandthunder":               {201, ConditionThunderstorm, "snow and thunder"},
+	"heavysnowandthunder":          {202, ConditionThunderstorm, "heavy snow and thunder"},
+	"lightssnowshowersandthunder":  {200, ConditionThunderstorm, "light snow showers and thunder"},
+	"snowshowersandthunder":        {201, ConditionThunderstorm, "snow showers and thunder"},
+	"heavysnowshowersandthunder":   {202, ConditionThunderstorm, "heavy snow showers and thunder"},
+}
+
+// metNorwayProvider queries the MET Norway Locationforecast 2.0 API.
+// MET Norway has no geocoder, so locations are resolved through Open-Meteo.
+type metNorwayProvider struct{}
+
+func (metNorwayProvider) Name() string {
+	return ProviderMetNorway
+}
+
+func (metNorwayProvider) Capabilities() Capabilities {
+	return Capabilities{RequiresAPIKey: false}
+}
+
+func (metNorwayProvider) Geocode(ctx context.Context, config Config, query string) ([]GeoResult, error) {
+	return openMeteoProvider{}.Geocode(ctx, config, query)
+}
+
+func (metNorwayProvider) FetchCurrent(ctx context.Context, config Config, location GeoResult) (*Weather, error) {
+	forecast, err := fetchMetNorwayForecast(
+		ctx, config.Client(), config.Endpoint(ProviderMetNorway, metNorwayEndpoint).BaseURL,
+		location.Latitude, location.Longitude,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch or decode data: %w", err)
+	}
+
+	weather, err := ConvertMetNorwayToWeather(forecast, location.Name, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	return &weather, nil
+}
+
+func (metNorwayProvider) FetchForecast(
+	ctx context.Context, config Config, location GeoResult, _ ForecastRequest,
+) (*Forecast, error) {
+	// The whole forecast comes in a single response, which is trimmed to the requested hours
+	metNorwayForecast, err := fetchMetNorwayForecast(
+		ctx, config.Client(), config.Endpoint(ProviderMetNorway, metNorwayEndpoint).BaseURL,
+		location.Latitude, location.Longitude,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch or decode data: %w", err)
+	}
+
+	forecast := ConvertMetNorwayToForecast(metNorwayForecast, location.Name)
+
+	return &forecast, nil
+}
+
+// fetchMetNorwayForecast returns the complete forecast for the given coordinates.
+// The terms of service require clients to identify themselves, which the HTTP client's
+// User-Agent does, to not ask again before
+// the Expires time and to revalidate with If-Modified-Since, so responses are cached on disk.
+func fetchMetNorwayForecast(
+	ctx context.Context, client *http.Client, baseURL string, latitude, longitude float64,
+) (MetNorwayForecast, error) {
+	// MET Norway asks for at most four decimals to keep its caches effective
+	cacheKey := fmt.Sprintf("%s|%.4f,%.4f", baseURL, latitude, longitude)
+	cached, _, hasCached := readCache[metNorwayCachedForecast]("metnorway", cacheKey)
+	if hasCached && time.Now().Before(cached.Expires) {
+		Logger.InfoContext(ctx, "MET Norway forecast cache hit", "expires", cached.Expires)
+		return cached.Forecast, nil
+	}
+
+	req, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodGet,
+		fmt.Sprintf("%s/complete?lat=%.4f&lon=%.4f", baseURL, latitude, longitude),
+		nil,
+	)
+	if err != nil {
+		return MetNorwayForecast{}, err
+	}
+	if hasCached && cached.LastModified != "" {
+		req.Header.Set("If-Modified-Since", cached.LastModified)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return MetNorwayForecast{}, newNetworkError(err)
+	}
+	defer func(body io.ReadCloser) {
+		_ = body.Close()
+	}(resp.Body)
+
+	entry := metNorwayCachedForecast{
+		LastModified: resp.Header.Get("Last-Modified"),
+	}
+	if expires, err := http.ParseTime(resp.Header.Get("Expires")); err == nil {
+		entry.Expires = expires
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && hasCached:
+		entry.Forecast = cached.Forecast
+		if entry.LastModified == "" {
+			entry.LastModified = cached.LastModified
+		}
+	case resp.StatusCode == http.StatusOK || resp.StatusCode == http.StatusNonAuthoritativeInfo:
+		// 203 signals a deprecated API version, the payload is still valid
+		if err = json.NewDecoder(resp.Body).Decode(&entry.Forecast); err != nil {
+			return MetNorwayForecast{}, &DecodeError{Err: err}
+		}
+	case resp.StatusCode == http.StatusForbidden:
+		return MetNorwayForecast{}, fmt.Errorf("access denied by MET Norway - check the User-Agent: %w", newHTTPStatusError(resp))
+	default:
+		return MetNorwayForecast{}, newHTTPStatusError(resp)
+	}
+
+	storeCache(ctx, "metnorway", cacheKey, entry)
+
+	return entry.Forecast, nil
+}
+
+// metNorwaySymbolFor looks up a symbol code, ignoring its _day/_night/_polartwilight variant
+func metNorwaySymbolFor(symbolCode string) (WeatherCondition, bool) {
+	base, _, _ := strings.Cut(symbolCode, "_")
+	symbol, ok := metNorwaySymbols[base]
+	return symbol, ok
+}
+
+// period returns the shortest period forecast from the time step along with its length,
+// the far end of the forecast only having 6 hour periods
+func (step MetNorwayTimestep) period() (*MetNorwayPeriod, time.Duration) {
+	if step.Data.Next1Hours != nil {
+		return step.Data.Next1Hours, time.Hour
+	}
+	return step.Data.Next6Hours, 6 * time.Hour
+}
+
+// convertMetNorwayPeriod returns the condition, precipitation amount and probability of
+// precipitation between 0 and 1 forecast for a period, which may be nil
+func convertMetNorwayPeriod(period *MetNorwayPeriod) (WeatherCondition, float64, *float64) {
+	condition := WeatherCondition{0, ConditionUnknown, "unknown conditions"}
+	if period == nil {
+		return condition, 0, nil
+	}
+
+	if symbol, ok := metNorwaySymbolFor(period.Summary.SymbolCode); ok {
+		condition = symbol
+	}
+	var pop *float64
+	if period.Details.ProbabilityOfPrecipitation != nil {
+		pop = ptr(*period.Details.ProbabilityOfPrecipitation / 100)
+	}
+	return condition, period.Details.PrecipitationAmount, pop
+}
+
+// ConvertMetNorwayToWeather converts the time step of the forecast covering now
+func ConvertMetNorwayToWeather(forecast MetNorwayForecast, cityName string, now time.Time) (Weather, error) {
+	timeseries := forecast.Properties.Timeseries
+	if len(timeseries) == 0 {
+		return Weather{}, fmt.Errorf("no forecast data returned for %s", cityName)
+	}
+
+	// Pick the latest time step that isn't in the future
+	step := timeseries[0]
+	for _, candidate := range timeseries[1:] {
+		if candidate.Time.After(now) {
+			break
+		}
+		step = candidate
+	}
+
+	period, _ := step.period()
+	condition, precipitation, probability := convertMetNorwayPeriod(period)
+	pop := 0.0
+	if probability != nil {
+		pop = *probability
+	}
+
+	details := step.Data.Instant.Details
+
+	var cloudCover *int
+	if details.CloudAreaFraction != nil {
+		cloudCover = ptr(int(*details.CloudAreaFraction + 0.5))
+	}
+
+	return Weather{
+		Weather: []WeatherCondition{condition},
+		Main: Measurements{
+			Temp:     details.AirTemperature,
+			Humidity: int(details.RelativeHumidity + 0.5),
+			DewPoint: details.DewPointTemperature,
+			Pressure: details.AirPressureAtSeaLevel,
+		},
+		Wind: Wind{
+			Speed: details.WindSpeed,
+			Deg:   int(details.WindFromDirection + 0.5),
+			Gust:  details.WindSpeedOfGust,
+		},
+		Rain: Precipitation{
+			OneHour: precipitation,
+		},
+		CloudCover: cloudCover,
+		Pop:        pop,
+		UVIndex:    details.UltravioletIndexClearSky,
+		Name:       cityName,
+		ObservedAt: step.Time,
+	}, nil
+}
+
+// ConvertMetNorwayToForecast converts the time steps of the forecast having a period forecast
+func ConvertMetNorwayToForecast(forecast MetNorwayForecast, cityName string) Forecast {
+	timeseries := forecast.Properties.Timeseries
+	converted := Forecast{
+		Hourly: make([]ForecastStep, 0, len(timeseries)),
+		Name:   cityName,
+	}
+
+	for _, step := range timeseries {
+		period, duration := step.period()
+		if period == nil {
+			continue
+		}
+		condition, precipitation, pop := convertMetNorwayPeriod(period)
+
+		details := step.Data.Instant.Details
+		converted.Hourly = append(converted.Hourly, ForecastStep{
+			Time:          step.Time,
+			Duration:      duration,
+			Condition:     condition,
+			Temp:          details.AirTemperature,
+			Precipitation: precipitation,
+			Pop:           pop,
+			Wind: Wind{
+				Speed: details.WindSpeed,
+				Deg:   int(details.WindFromDirection + 0.5),
+				Gust:  details.WindSpeedOfGust,
+			},
+		})
+	}
+
+	return converted
+}
@@ -0,0 +1,107 @@
+package weather
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// metNorwayFixture forecasts two hours from the given time, then a 6 hour period, followed
+// by a time step without any period forecast
+func metNorwayFixture(start time.Time) string {
+	step := func(t time.Time, temp string, period string) string {
+		return `{"time": "` + t.UTC().Format(time.RFC3339) + `", "data": {
+			"instant": {"details": {"air_pressure_at_sea_level": 1021.4, "air_temperature": ` + temp + `,
+				"cloud_area_fraction": 62.5, "relative_humidity": 71.6, "wind_from_direction": 301.8,
+				"wind_speed": 4.3}}` + period + `}}`
+	}
+	return `{"properties": {"timeseries": [` +
+		step(start, "1.5", `, "next_1_hours": {"summary": {"symbol_code": "lightsnowshowers_night"},
+			"details": {"precipitation_amount": 0.2, "probability_of_precipitation": 40}}`) + "," +
+		step(start.Add(time.Hour), "2.5", `, "next_1_hours": {"summary": {"symbol_code": "cloudy"},
+			"details": {"precipitation_amount": 0}}`) + "," +
+		step(start.Add(2*time.Hour), "3.5", `, "next_6_hours": {"summary": {"symbol_code": "clearsky_day"},
+			"details": {"precipitation_amount": 0}}`) + "," +
+		step(start.Add(8*time.Hour), "4.5", "") +
+		`]}}`
+}
+
+func TestMetNorwayFetchWeather(t *testing.T) {
+	start := time.Now().Truncate(time.Hour)
+	var requests atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /complete", func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.Header.Get("User-Agent") != UserAgent {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.Header().Set("Expires", time.Now().Add(time.Hour).UTC().Format(http.TimeFormat))
+		serveJSON(metNorwayFixture(start))(w, r)
+	})
+	config := stubConfig(t, mux)
+	config.Provider = ProviderMetNorway
+	config.Latitude, config.Longitude = ptr(59.9139), ptr(10.7522)
+
+	for range 2 {
+		weather, err := FetchWeather(context.Background(), config)
+		if err != nil {
+			t.Fatalf("fetching failed: %v", err)
+		}
+		if weather.Main.Temp != 1.5 || weather.Weather[0].Description != "light snow showers" {
+			t.Errorf("got %v °C with %q, want the current hour", weather.Main.Temp, weather.Weather[0].Description)
+		}
+		if weather.Pop != 0.4 || weather.Main.Humidity != 72 || weather.Wind.Deg != 302 {
+			t.Errorf("got probability %v, humidity %d%% and wind from %d°, want 0.4, 72%% and 302°",
+				weather.Pop, weather.Main.Humidity, weather.Wind.Deg)
+		}
+	}
+
+	if got := requests.Load(); got != 1 {
+		t.Errorf("got %d requests, want the forecast reused until it expires", got)
+	}
+}
+
+func TestConvertMetNorway(t *testing.T) {
+	start := time.Date(2026, 3, 14, 12, 0, 0, 0, time.UTC)
+	fixture := decodeFixture[MetNorwayForecast](t, metNorwayFixture(start))
+
+	tests := []struct {
+		name     string
+		now      time.Time
+		wantTemp float64
+	}{
+		{"before the forecast", start.Add(-time.Hour), 1.5},
+		{"during a step", start.Add(90 * time.Minute), 2.5},
+		{"beyond the forecast", start.Add(48 * time.Hour), 4.5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			weather, err := ConvertMetNorwayToWeather(fixture, "Oslo", tt.now)
+			if err != nil {
+				t.Fatalf("conversion failed: %v", err)
+			}
+			if weather.Main.Temp != tt.wantTemp {
+				t.Errorf("got %v °C, want %v °C", weather.Main.Temp, tt.wantTemp)
+			}
+		})
+	}
+
+	if _, err := ConvertMetNorwayToWeather(MetNorwayForecast{}, "Oslo", start); err == nil {
+		t.Error("converting an empty forecast succeeded")
+	}
+
+	// The last time step has no period forecast
+	forecast := ConvertMetNorwayToForecast(fixture, "Oslo")
+	durations := make([]time.Duration, len(forecast.Hourly))
+	for i, step := range forecast.Hourly {
+		durations[i] = step.Duration
+	}
+	if want := []time.Duration{time.Hour, time.Hour, 6 * time.Hour}; !slices.Equal(durations, want) {
+		t.Errorf("got steps of %v, want %v", durations, want)
+	}
+}
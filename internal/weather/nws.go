@@ -0,0 +1,400 @@
+package weather
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/ashish0kumar/stormy/internal/units"
+)
+
+const ProviderNWS = "NWS"
+
+// nwsEndpoint has no geocoder, locations are resolved with the Open-Meteo endpoints
+var nwsEndpoint = Endpoint{
+	BaseURL: "https://api.weather.gov",
+}
+
+func init() {
+	RegisterProvider(nwsProvider{})
+}
+
+// NWSValue is a quantitative value as reported by api.weather.gov, null when unavailable
+type NWSValue struct {
+	UnitCode string   `json:"unitCode"`
+	Value    *float64 `json:"value"`
+}
+
+type NWSPoint struct {
+	Properties struct {
+		GridID              string `json:"gridId"`
+		GridX               int    `json:"gridX"`
+		GridY               int    `json:"gridY"`
+		Forecast            string `json:"forecast"`
+		ForecastHourly      string `json:"forecastHourly"`
+		ObservationStations string `json:"observationStations"`
+		TimeZone            string `json:"timeZone"`
+		RelativeLocation    struct {
+			Properties struct {
+				City  string `json:"city"`
+				State string `json:"state"`
+			} `json:"properties"`
+		} `json:"relativeLocation"`
+	} `json:"properties"`
+}
+
+type NWSStations struct {
+	Features []struct {
+		Properties struct {
+			StationIdentifier string `json:"stationIdentifier"`
+			Name              string `json:"name"`
+		} `json:"properties"`
+	} `json:"features"`
+}
+
+type NWSObservation struct {
+	Properties struct {
+		Timestamp             time.Time `json:"timestamp"`
+		TextDescription       string    `json:"textDescription"`
+		Icon                  string    `json:"icon"`
+		Temperature           NWSValue  `json:"temperature"`
+		Dewpoint              NWSValue  `json:"dewpoint"`
+		HeatIndex             NWSValue  `json:"heatIndex"`
+		WindChill             NWSValue  `json:"windChill"`
+		RelativeHumidity      NWSValue  `json:"relativeHumidity"`
+		SeaLevelPressure      NWSValue  `json:"seaLevelPressure"`
+		BarometricPressure    NWSValue  `json:"barometricPressure"`
+		Visibility            NWSValue  `json:"visibility"`
+		WindSpeed             NWSValue  `json:"windSpeed"`
+		WindGust              NWSValue  `json:"windGust"`
+		WindDirection         NWSValue  `json:"windDirection"`
+		PrecipitationLastHour NWSValue  `json:"precipitationLastHour"`
+		CloudLayers           []struct {
+			Amount string `json:"amount"`
+		} `json:"cloudLayers"`
+	} `json:"properties"`
+}
+
+type NWSForecast struct {
+	Properties struct {
+		Periods []struct {
+			StartTime                  time.Time `json:"startTime"`
+			Temperature                float64   `json:"temperature"`
+			TemperatureUnit            string    `json:"temperatureUnit"`
+			ProbabilityOfPrecipitation NWSValue  `json:"probabilityOfPrecipitation"`
+			ShortForecast              string    `json:"shortForecast"`
+			Icon                       string    `json:"icon"`
+		} `json:"periods"`
+	} `json:"properties"`
+}
+
+// nwsGrid is the part of a points lookup worth keeping: it never changes for a location
+type nwsGrid struct {
+	Office   string `json:"office"`
+	GridX    int    `json:"grid_x"`
+	GridY    int    `json:"grid_y"`
+	Forecast string `json:"forecast"`
+	Station  string `json:"station"`
+	City     string `json:"city"`
+	State    string `json:"state"`
+	TimeZone string `json:"time_zone"`
+}
+
+// nwsIcons maps the icon names used by api.weather.gov to their closest condition
+var nwsIcons = map[string]WeatherCondition{
+	"skc":             {800, ConditionClear, "clear"},
+	"few":             {801, ConditionClouds, "a few clouds"},
+	"sct":             {802, ConditionClouds, "partly cloudy"},
+	"bkn":             {803, ConditionClouds, "mostly cloudy"},
+	"ovc":             {804, ConditionClouds, "overcast"},
+	"wind_skc":        {800, ConditionClear, "clear and windy"},
+	"wind_few":        {801, ConditionClouds, "a few clouds and windy"},
+	"wind_sct":        {802, ConditionClouds, "partly cloudy and windy"},
+	"wind_bkn":        {803, ConditionClouds, "mostly cloudy and windy"},
+	"wind_ovc":        {804, ConditionClouds, "overcast and windy"},
+	"snow":            {601, ConditionSnow, "snow"},
+	"rain_snow":       {616, ConditionSnow, "rain and snow"},
+	"rain_sleet":      {612, ConditionSnow, "rain and sleet"},
+	"snow_sleet":      {611, ConditionSnow, "snow and sleet"},
+	"fzra":            {511, ConditionRain, "freezing rain"},
+	"rain_fzra":       {511, ConditionRain, "rain and freezing rain"},
+	"snow_fzra":       {611, ConditionSnow, "snow and freezing rain"},
+	"sleet":           {611, ConditionSnow, "sleet"},
+	"rain":            {501, ConditionRain, "rain"},
+	"rain_showers":    {521, ConditionRain, "rain showers"},
+	"rain_showers_hi": {520, ConditionRain, "rain showers"},
+	"tsra":            {201, ConditionThunderstorm, "thunderstorm"},
+	"tsra_sct":        {200, ConditionThunderstorm, "scattered thunderstorms"},
+	"tsra_hi":         {200, ConditionThunderstorm, "isolated thunderstorms"},
+	"tornado":         {781, ConditionTornado, "tornado"},
+	"hurricane":       {781, ConditionSquall, "hurricane conditions"},
+	"tropical_storm":  {781, ConditionSquall, "tropical storm conditions"},
+	"dust":            {761, ConditionDust, "dust"},
+	"smoke":           {711, ConditionSmoke, "smoke"},
+	"haze":            {721, ConditionHaze, "haze"},
+	"hot":             {800, ConditionClear, "hot"},
+	"cold":            {800, ConditionClear, "cold"},
+	"blizzard":        {602, ConditionSnow, "blizzard"},
+	"fog":             {741, ConditionFog, "fog"},
+}
+
+// nwsCloudCover maps the METAR cloud amounts of observations to the cloud cover they
+// stand for, in percent: the middle of their range of oktas
+var nwsCloudCover = map[string]int{
+	"SKC": 0,
+	"CLR": 0,
+	"FEW": 19,
+	"SCT": 44,
+	"BKN": 75,
+	"OVC": 100,
+	"VV":  100,
+}
+
+// nwsProvider queries the US National Weather Service API at api.weather.gov.
+// It only covers the United States and has no geocoder, so locations are resolved through Open-Meteo.
+type nwsProvider struct{}
+
+func (nwsProvider) Name() string {
+	return ProviderNWS
+}
+
+func (nwsProvider) Capabilities() Capabilities {
+	return Capabilities{RequiresAPIKey: false}
+}
+
+func (nwsProvider) Geocode(ctx context.Context, config Config, query string) ([]GeoResult, error) {
+	return openMeteoProvider{}.Geocode(ctx, config, query)
+}
+
+func (nwsProvider) FetchCurrent(ctx context.Context, config Config, location GeoResult) (*Weather, error) {
+	client := config.Client()
+	baseURL := config.Endpoint(ProviderNWS, nwsEndpoint).BaseURL
+	grid, err := resolveNWSGrid(ctx, client, baseURL, location.Latitude, location.Longitude)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve forecast grid: %w", err)
+	}
+
+	observation, err := fetchNWS[NWSObservation](
+		ctx,
+		client,
+		fmt.Sprintf("%s/stations/%s/observations/latest", baseURL, url.PathEscape(grid.Station)),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch or decode data: %w", err)
+	}
+
+	// The gridpoint forecast only adds the precipitation probability, so the
+	// observation is still worth showing when the forecast endpoint is unavailable
+	forecast, err := fetchNWS[NWSForecast](ctx, client, rebaseNWSURL(grid.Forecast, baseURL)+"?units=si")
+	if err != nil {
+		forecast = NWSForecast{}
+	}
+
+	cityName := location.Name
+	if cityName == "" {
+		cityName = grid.City
+	}
+
+	weather, err := ConvertNWSToWeather(observation, forecast, cityName)
+	if err != nil {
+		return nil, err
+	}
+	if grid.TimeZone != "" {
+		weather.Timezone = &Timezone{Name: grid.TimeZone}
+	}
+
+	return &weather, nil
+}
+
+// fetchNWS sends a request accepting the GeoJSON api.weather.gov serves
+func fetchNWS[T any](ctx context.Context, client *http.Client, u string) (T, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	req.Header.Set("Accept", "application/geo+json")
+
+	return fetchAndUnmarshalRequest[T](client, req)
+}
+
+// resolveNWSGrid maps coordinates to their forecast office, grid cell and nearest
+// observation station. The mapping never changes for a location, so it's cached forever.
+func resolveNWSGrid(
+	ctx context.Context, client *http.Client, baseURL string, latitude, longitude float64,
+) (nwsGrid, error) {
+	// api.weather.gov redirects requests with more than four decimals. Grids are cached per
+	// base URL, a mirror or stub server may not map coordinates the same way.
+	coordinates := fmt.Sprintf("%.4f,%.4f", latitude, longitude)
+	cacheKey := baseURL + "|" + coordinates
+	if grid, _, ok := readCache[nwsGrid]("nws-grid", cacheKey); ok {
+		Logger.DebugContext(ctx, "NWS grid cache hit", "office", grid.Office, "station", grid.Station)
+		return grid, nil
+	}
+
+	point, err := fetchNWS[NWSPoint](ctx, client, fmt.Sprintf("%s/points/%s", baseURL, coordinates))
+	if errors.Is(err, ErrLocationNotFound) {
+		return nwsGrid{}, fmt.Errorf("%w (the National Weather Service only covers the United States)", err)
+	}
+	if err != nil {
+		return nwsGrid{}, err
+	}
+
+	stations, err := fetchNWS[NWSStations](ctx, client, rebaseNWSURL(point.Properties.ObservationStations, baseURL))
+	if err != nil {
+		return nwsGrid{}, err
+	}
+	if len(stations.Features) == 0 {
+		return nwsGrid{}, fmt.Errorf("no observation station found near %s: %w", coordinates, ErrLocationNotFound)
+	}
+
+	grid := nwsGrid{
+		Office:   point.Properties.GridID,
+		GridX:    point.Properties.GridX,
+		GridY:    point.Properties.GridY,
+		Forecast: point.Properties.Forecast,
+		// Stations are sorted by distance from the requested point
+		Station: stations.Features[0].Properties.StationIdentifier,
+		City:    point.Properties.RelativeLocation.Properties.City,
+		State:   point.Properties.RelativeLocation.Properties.State,
+		// Grids cached by older versions have no timezone, which is only informative
+		TimeZone: point.Properties.TimeZone,
+	}
+
+	storeCache(ctx, "nws-grid", cacheKey, grid)
+
+	return grid, nil
+}
+
+// rebaseNWSURL points a URL returned by api.weather.gov at the configured base URL,
+// so that following links keeps going through a mirror or proxy
+func rebaseNWSURL(u, baseURL string) string {
+	if rest, ok := strings.CutPrefix(u, nwsEndpoint.BaseURL); ok {
+		return baseURL + rest
+	}
+	return u
+}
+
+// nwsIconCondition extracts the condition from an icon URL such as
+// https://api.weather.gov/icons/land/day/rain_showers,40?size=medium
+func nwsIconCondition(iconURL string) (WeatherCondition, bool) {
+	u, err := url.Parse(iconURL)
+	if err != nil {
+		return WeatherCondition{}, false
+	}
+
+	// Icons made of two conditions end with both, e.g. /day/tsra_hi,20/rain,50
+	segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+	for _, segment := range segments {
+		if segment == "day" || segment == "night" {
+			continue
+		}
+		name, _, _ := strings.Cut(segment, ",")
+		if info, ok := nwsIcons[name]; ok {
+			return info, true
+		}
+	}
+
+	return WeatherCondition{}, false
+}
+
+// ConvertNWSToWeather converts an observation, completed by the gridpoint forecast. It fails
+// when neither has a temperature, rather than showing a made up one.
+func ConvertNWSToWeather(observation NWSObservation, forecast NWSForecast, cityName string) (Weather, error) {
+	props := observation.Properties
+
+	condition := WeatherCondition{0, ConditionUnknown, "unknown conditions"}
+	if info, ok := nwsIconCondition(props.Icon); ok {
+		condition = info
+	}
+	if props.TextDescription != "" {
+		condition.Description = strings.ToLower(props.TextDescription)
+	}
+
+	valueOr := func(v NWSValue, fallback float64) float64 {
+		if v.Value == nil {
+			return fallback
+		}
+		return *v.Value
+	}
+
+	// Observations are normally reported in km/h, but some stations report m/s
+	windSpeed := func(v NWSValue) *float64 {
+		if v.Value == nil || strings.HasSuffix(v.UnitCode, "m_s-1") {
+			return v.Value
+		}
+		return ptr(*v.Value / units.MpsToKph)
+	}
+
+	speed := 0.0
+	if v := windSpeed(props.WindSpeed); v != nil {
+		speed = *v
+	}
+
+	temperature := props.Temperature.Value
+	pop := 0.0
+	if periods := forecast.Properties.Periods; len(periods) > 0 {
+		// Stations occasionally miss readings, the current forecast period is the next best thing
+		if temperature == nil && periods[0].TemperatureUnit == "C" {
+			temperature = ptr(periods[0].Temperature)
+		}
+		pop = valueOr(periods[0].ProbabilityOfPrecipitation, 0) / 100
+	}
+	if temperature == nil {
+		return Weather{}, &DecodeError{Err: errors.New("no temperature observed or forecast")}
+	}
+
+	// The heat index and the wind chill are only reported when they differ from the temperature
+	feelsLike := props.HeatIndex.Value
+	if feelsLike == nil {
+		feelsLike = props.WindChill.Value
+	}
+	if feelsLike == nil && props.Temperature.Value != nil {
+		feelsLike = props.Temperature.Value
+	}
+
+	// Pressures are reported in Pa
+	pressure := props.SeaLevelPressure.Value
+	if pressure == nil {
+		pressure = props.BarometricPressure.Value
+	}
+	if pressure != nil {
+		pressure = ptr(*pressure / 100)
+	}
+
+	// The sky is at least as covered as its most covering layer
+	var cloudCover *int
+	for _, layer := range props.CloudLayers {
+		if cover, ok := nwsCloudCover[layer.Amount]; ok && (cloudCover == nil || cover > *cloudCover) {
+			cloudCover = ptr(cover)
+		}
+	}
+
+	return Weather{
+		Weather: []WeatherCondition{condition},
+		Main: Measurements{
+			Temp:      *temperature,
+			Humidity:  int(valueOr(props.RelativeHumidity, 0) + 0.5),
+			FeelsLike: feelsLike,
+			DewPoint:  props.Dewpoint.Value,
+			Pressure:  pressure,
+		},
+		Wind: Wind{
+			Speed: speed,
+			Deg:   int(valueOr(props.WindDirection, 0) + 0.5),
+			Gust:  windSpeed(props.WindGust),
+		},
+		Rain: Precipitation{
+			OneHour: valueOr(props.PrecipitationLastHour, 0),
+		},
+		CloudCover: cloudCover,
+		Pop:        pop,
+		Visibility: props.Visibility.Value,
+		Name:       cityName,
+		ObservedAt: props.Timestamp,
+	}, nil
+}
@@ -0,0 +1,173 @@
+package weather
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// nwsStub serves the points, stations, observation and forecast endpoints of api.weather.gov,
+// linking to each other with absolute api.weather.gov URLs as the real API does
+func nwsStub(observation, forecast string, points *atomic.Int32) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /points/{coordinates}", func(w http.ResponseWriter, r *http.Request) {
+		points.Add(1)
+		serveJSON(`{"properties": {"gridId": "LOT", "gridX": 75, "gridY": 72,
+			"forecast": "https://api.weather.gov/gridpoints/LOT/75,72/forecast",
+			"observationStations": "https://api.weather.gov/gridpoints/LOT/75,72/stations",
+			"timeZone": "America/Chicago",
+			"relativeLocation": {"properties": {"city": "Chicago", "state": "IL"}}}}`)(w, r)
+	})
+	mux.HandleFunc("GET /gridpoints/LOT/75,72/stations", serveJSON(
+		`{"features": [{"properties": {"stationIdentifier": "KMDW", "name": "Chicago Midway"}}]}`,
+	))
+	mux.HandleFunc("GET /stations/KMDW/observations/latest", serveJSON(observation))
+	mux.HandleFunc("GET /gridpoints/LOT/75,72/forecast", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("units") != "si" {
+			http.Error(w, "expected SI units", http.StatusBadRequest)
+			return
+		}
+		serveJSON(forecast)(w, r)
+	})
+	return mux
+}
+
+// nwsObservation returns an observation with the given temperature in °C, or null
+func nwsObservation(temperature string) string {
+	return `{"properties": {
+		"timestamp": "2026-03-14T17:53:00+00:00",
+		"textDescription": "Mostly Cloudy",
+		"icon": "https://api.weather.gov/icons/land/day/bkn?size=medium",
+		"temperature": {"unitCode": "wmoUnit:degC", "value": ` + temperature + `},
+		"windChill": {"unitCode": "wmoUnit:degC", "value": null},
+		"heatIndex": {"unitCode": "wmoUnit:degC", "value": null},
+		"relativeHumidity": {"unitCode": "wmoUnit:percent", "value": 65.2},
+		"seaLevelPressure": {"unitCode": "wmoUnit:Pa", "value": 101690},
+		"visibility": {"unitCode": "wmoUnit:m", "value": 16090},
+		"windSpeed": {"unitCode": "wmoUnit:km_h-1", "value": 18},
+		"windGust": {"unitCode": "wmoUnit:km_h-1", "value": null},
+		"windDirection": {"unitCode": "wmoUnit:degree_(angle)", "value": 250},
+		"cloudLayers": [{"amount": "FEW"}, {"amount": "BKN"}]
+	}}`
+}
+
+// nwsForecast returns a forecast whose current period has the given temperature and unit
+func nwsForecast(temperature, unit string) string {
+	return `{"properties": {"periods": [{"startTime": "2026-03-14T12:00:00-05:00",
+		"temperature": ` + temperature + `, "temperatureUnit": "` + unit + `",
+		"probabilityOfPrecipitation": {"unitCode": "wmoUnit:percent", "value": 30},
+		"shortForecast": "Mostly Cloudy"}]}}`
+}
+
+func TestNWSFetchWeather(t *testing.T) {
+	tests := []struct {
+		name        string
+		observation string
+		forecast    string
+		wantTemp    float64
+		wantErr     bool
+	}{
+		{"observed", nwsObservation("3.3"), nwsForecast("5", "C"), 3.3, false},
+		{"missing observation", nwsObservation("null"), nwsForecast("5", "C"), 5, false},
+		{"forecast in Fahrenheit", nwsObservation("null"), nwsForecast("41", "F"), 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var points atomic.Int32
+			config := stubConfig(t, nwsStub(tt.observation, tt.forecast, &points))
+			config.Provider = ProviderNWS
+			config.Latitude, config.Longitude = ptr(41.8781), ptr(-87.6298)
+
+			weather, err := FetchWeather(context.Background(), config)
+			if tt.wantErr {
+				var decodeErr *DecodeError
+				if !errors.As(err, &decodeErr) {
+					t.Fatalf("got error %v, want a decoding error rather than a made up temperature", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("fetching failed: %v", err)
+			}
+
+			if weather.Main.Temp != tt.wantTemp {
+				t.Errorf("got %v °C, want %v °C", weather.Main.Temp, tt.wantTemp)
+			}
+			if got := weather.Weather[0]; got.Main != ConditionClouds || got.Description != "mostly cloudy" {
+				t.Errorf("got condition %+v, want mostly cloudy", got)
+			}
+			if weather.Wind.Speed != 5 || weather.Wind.Deg != 250 {
+				t.Errorf("got wind %v m/s from %d°, want 5 m/s from 250°", weather.Wind.Speed, weather.Wind.Deg)
+			}
+			if weather.Main.Pressure == nil || *weather.Main.Pressure != 1016.9 {
+				t.Errorf("got pressure %v, want 1016.9 hPa", weather.Main.Pressure)
+			}
+			if weather.CloudCover == nil || *weather.CloudCover != 75 || weather.Pop != 0.3 {
+				t.Errorf("got cloud cover %v and probability %v, want 75%% and 0.3", weather.CloudCover, weather.Pop)
+			}
+			if weather.Timezone == nil || weather.Timezone.Name != "America/Chicago" {
+				t.Errorf("got timezone %+v, want America/Chicago", weather.Timezone)
+			}
+		})
+	}
+}
+
+func TestNWSGridCachedPerBaseURL(t *testing.T) {
+	var firstPoints, secondPoints atomic.Int32
+	config := stubConfig(t, nwsStub(nwsObservation("3.3"), nwsForecast("5", "C"), &firstPoints))
+	config.Provider = ProviderNWS
+	config.Latitude, config.Longitude = ptr(41.8781), ptr(-87.6298)
+
+	for range 2 {
+		if _, err := FetchWeather(context.Background(), config); err != nil {
+			t.Fatalf("fetching failed: %v", err)
+		}
+	}
+	if got := firstPoints.Load(); got != 1 {
+		t.Errorf("got %d points lookups, want the grid cached after the first one", got)
+	}
+
+	// Another server sharing the cache directory must resolve the grid again
+	second := httptest.NewServer(nwsStub(nwsObservation("3.3"), nwsForecast("5", "C"), &secondPoints))
+	defer second.Close()
+	config.Endpoints[ProviderNWS] = Endpoint{BaseURL: second.URL}
+
+	if _, err := FetchWeather(context.Background(), config); err != nil {
+		t.Fatalf("fetching failed: %v", err)
+	}
+	if got := secondPoints.Load(); got != 1 {
+		t.Errorf("got %d points lookups on the other server, want 1", got)
+	}
+}
+
+func TestNWSPointsErrors(t *testing.T) {
+	const hint = "only covers the United States"
+
+	outside := http.NewServeMux()
+	outside.HandleFunc("GET /points/{coordinates}", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"title": "Data Unavailable For Requested Point"}`, http.StatusNotFound)
+	})
+	config := stubConfig(t, outside)
+	config.Provider = ProviderNWS
+	config.Latitude, config.Longitude = ptr(48.8566), ptr(2.3522)
+
+	_, err := FetchWeather(context.Background(), config)
+	if !errors.Is(err, ErrLocationNotFound) || !strings.Contains(err.Error(), hint) {
+		t.Errorf("got error %v, want a location outside of the coverage", err)
+	}
+
+	// Being offline has nothing to do with the coverage
+	unreachable := httptest.NewServer(http.NotFoundHandler())
+	unreachable.Close()
+	config.Endpoints[ProviderNWS] = Endpoint{BaseURL: unreachable.URL}
+
+	_, err = FetchWeather(context.Background(), config)
+	if !IsNetworkError(err) || strings.Contains(err.Error(), hint) {
+		t.Errorf("got error %v, want a network error without the coverage hint", err)
+	}
+}
@@ -0,0 +1,388 @@
+package weather
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const ProviderOpenMeteo = "OpenMeteo"
+
+var openMeteoEndpoint = Endpoint{
+	BaseURL:      "https://api.open-meteo.com/v1",
+	GeocodingURL: "https://geocoding-api.open-meteo.com/v1",
+}
+
+func init() {
+	RegisterProvider(openMeteoProvider{})
+}
+
+type GeoResponse struct {
+	Results []GeoResult `json:"results"`
+}
+
+type OpenMeteoWeather struct {
+	Latitude         float64 `json:"latitude"`
+	Longitude        float64 `json:"longitude"`
+	Timezone         string  `json:"timezone"`
+	UTCOffsetSeconds int     `json:"utc_offset_seconds"`
+	Current          struct {
+		Time                int64    `json:"time"`
+		Interval            int      `json:"interval"`
+		Temperature2m       float64  `json:"temperature_2m"`
+		ApparentTemperature *float64 `json:"apparent_temperature"`
+		DewPoint2m          *float64 `json:"dew_point_2m"`
+		WeatherCode         int      `json:"weather_code"`
+		Precipitation       float64  `json:"precipitation"`
+		RelativeHumidity2m  int      `json:"relative_humidity_2m"`
+		CloudCover          *int     `json:"cloud_cover"`
+		PressureMSL         *float64 `json:"pressure_msl"`
+		Visibility          *float64 `json:"visibility"`
+		UVIndex             *float64 `json:"uv_index"`
+		WindSpeed10m        float64  `json:"wind_speed_10m"`
+		WindDirection10m    int      `json:"wind_direction_10m"`
+		WindGusts10m        *float64 `json:"wind_gusts_10m"`
+	} `json:"current"`
+	Daily struct {
+		Sunrise          []int64    `json:"sunrise"`
+		Sunset           []int64    `json:"sunset"`
+		Temperature2mMax []*float64 `json:"temperature_2m_max"`
+		Temperature2mMin []*float64 `json:"temperature_2m_min"`
+	} `json:"daily"`
+}
+
+// openMeteoCurrentVariables are the current conditions requested from Open-Meteo
+const openMeteoCurrentVariables = "temperature_2m,apparent_temperature,dew_point_2m,weather_code,precipitation," +
+	"relative_humidity_2m,cloud_cover,pressure_msl,visibility,uv_index," +
+	"wind_speed_10m,wind_direction_10m,wind_gusts_10m"
+
+// openMeteoProvider queries the free Open-Meteo forecast and geocoding APIs
+type openMeteoProvider struct{}
+
+func (openMeteoProvider) Name() string {
+	return ProviderOpenMeteo
+}
+
+func (openMeteoProvider) Capabilities() Capabilities {
+	return Capabilities{RequiresAPIKey: false}
+}
+
+func (openMeteoProvider) Geocode(ctx context.Context, config Config, query string) ([]GeoResult, error) {
+	locations, err := SearchOpenMeteoLocations(ctx, config, query)
+	if err != nil {
+		return nil, fmt.Errorf("geocoding failed: %w", err)
+	}
+
+	return locations, nil
+}
+
+func (openMeteoProvider) FetchCurrent(ctx context.Context, config Config, location GeoResult) (*Weather, error) {
+	openMeteoWeather, err := fetchAndUnmarshal[OpenMeteoWeather](
+		ctx,
+		config.Client(),
+		"%s/forecast?latitude=%f&longitude=%f&current=%s"+
+			"&daily=sunrise,sunset,temperature_2m_max,temperature_2m_min&forecast_days=1"+
+			"&timezone=auto&timeformat=unixtime&wind_speed_unit=ms&temperature_unit=celsius",
+		config.Endpoint(ProviderOpenMeteo, openMeteoEndpoint).BaseURL,
+		location.Latitude,
+		location.Longitude,
+		openMeteoCurrentVariables,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch or decode data: %w", err)
+	}
+
+	weather := ConvertOpenMeteoToWeather(openMeteoWeather, location.Name)
+
+	return &weather, nil
+}
+
+// openMeteoGeocodingCount is the number of candidates requested from the geocoder, large
+// enough for ambiguous names like Springfield to include the intended place
+const openMeteoGeocodingCount = 20
+
+// SearchOpenMeteoLocations geocodes a "city[, region][, country]" query. The geocoder only
+// understands names, so the region and country are matched against its results instead.
+func SearchOpenMeteoLocations(ctx context.Context, config Config, query string) ([]GeoResult, error) {
+	parsed, err := ParseLocationQuery(query)
+	if err != nil {
+		return nil, err
+	}
+
+	params := url.Values{}
+	params.Set("name", parsed.Name)
+	params.Set("count", strconv.Itoa(openMeteoGeocodingCount))
+	if countryCode := parsed.CountryCode(); countryCode != "" {
+		params.Set("countryCode", countryCode)
+	}
+
+	geo, err := fetchAndUnmarshal[GeoResponse](
+		ctx,
+		config.Client(),
+		"%s/search?%s",
+		config.Endpoint(ProviderOpenMeteo, openMeteoEndpoint).GeocodingURL,
+		params.Encode(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]GeoResult, 0, len(geo.Results))
+	for _, result := range geo.Results {
+		if parsed.Matches(result) {
+			results = append(results, result)
+		}
+	}
+
+	if len(results) == 0 {
+		return nil, fmt.Errorf("no results found for city query \"%s\": %w", query, ErrLocationNotFound)
+	}
+
+	return results, nil
+}
+
+// wmoConditions maps the WMO 4677 weather codes reported by Open-Meteo to their closest
+// OpenWeatherMap condition, which picks the icon, along with their official description in
+// lower case like the descriptions of the other providers
+var wmoConditions = map[int]WeatherCondition{
+	0:  {800, ConditionClear, "clear sky"},
+	1:  {801, ConditionClear, "mainly clear"},
+	2:  {801, ConditionClouds, "partly cloudy"},
+	3:  {804, ConditionClouds, "overcast"},
+	45: {741, ConditionFog, "fog"},
+	48: {741, ConditionFog, "depositing rime fog"},
+	51: {300, ConditionDrizzle, "light drizzle"},
+	53: {301, ConditionDrizzle, "moderate drizzle"},
+	55: {302, ConditionDrizzle, "dense drizzle"},
+	56: {301, ConditionDrizzle, "light freezing drizzle"},
+	57: {302, ConditionDrizzle, "dense freezing drizzle"},
+	61: {500, ConditionRain, "slight rain"},
+	63: {501, ConditionRain, "moderate rain"},
+	65: {502, ConditionRain, "heavy rain"},
+	66: {511, ConditionRain, "light freezing rain"},
+	67: {511, ConditionRain, "heavy freezing rain"},
+	71: {600, ConditionSnow, "slight snow fall"},
+	73: {601, ConditionSnow, "moderate snow fall"},
+	75: {602, ConditionSnow, "heavy snow fall"},
+	77: {600, ConditionSnow, "snow grains"},
+	80: {520, ConditionRain, "slight rain showers"},
+	81: {521, ConditionRain, "moderate rain showers"},
+	82: {522, ConditionRain, "violent rain showers"},
+	85: {620, ConditionSnow, "slight snow showers"},
+	86: {622, ConditionSnow, "heavy snow showers"},
+	95: {211, ConditionThunderstorm, "thunderstorm"},
+	96: {201, ConditionThunderstorm, "thunderstorm with slight hail"},
+	99: {202, ConditionThunderstorm, "thunderstorm with heavy hail"},
+}
+
+// WMOCondition returns the condition matching a WMO 4677 weather code
+func WMOCondition(code int) WeatherCondition {
+	if condition, ok := wmoConditions[code]; ok {
+		return condition
+	}
+	return WeatherCondition{0, ConditionUnknown, "unknown weather code"}
+}
+
+// CodeToSentence describes a WMO 4677 weather code, like "slight rain showers"
+func CodeToSentence(code int) string {
+	return WMOCondition(code).Description
+}
+
+func ConvertOpenMeteoToWeather(om OpenMeteoWeather, cityName string) Weather {
+	current := om.Current
+	weather := Weather{
+		Weather: []WeatherCondition{WMOCondition(current.WeatherCode)},
+		Main: Measurements{
+			Temp:      current.Temperature2m,
+			Humidity:  current.RelativeHumidity2m,
+			FeelsLike: current.ApparentTemperature,
+			DewPoint:  current.DewPoint2m,
+			Pressure:  current.PressureMSL,
+		},
+		Wind: Wind{
+			Speed: current.WindSpeed10m,
+			Deg:   current.WindDirection10m,
+			Gust:  current.WindGusts10m,
+		},
+		Rain: Precipitation{
+			OneHour: current.Precipitation,
+		},
+		CloudCover: current.CloudCover,
+		Pop:        0, // Not provided by Open-Meteo
+		Visibility: current.Visibility,
+		UVIndex:    current.UVIndex,
+		Timezone:   &Timezone{Name: om.Timezone, OffsetSeconds: om.UTCOffsetSeconds},
+		Name:       cityName,
+	}
+
+	if current.Time > 0 {
+		weather.ObservedAt = time.Unix(current.Time, 0)
+	}
+
+	// Only today is requested, so the daily values are the first ones
+	daily := om.Daily
+	// Days without sunrise or sunset, as in polar regions, have no meaningful time
+	if len(daily.Sunrise) > 0 && len(daily.Sunset) > 0 && daily.Sunrise[0] != daily.Sunset[0] {
+		weather.Sunrise = ptr(time.Unix(daily.Sunrise[0], 0))
+		weather.Sunset = ptr(time.Unix(daily.Sunset[0], 0))
+	}
+	if len(daily.Temperature2mMin) > 0 && len(daily.Temperature2mMax) > 0 {
+		weather.Main.TempMin, weather.Main.TempMax = daily.Temperature2mMin[0], daily.Temperature2mMax[0]
+	}
+
+	return weather
+}
+
+type OpenMeteoForecast struct {
+	Timezone         string `json:"timezone"`
+	UTCOffsetSeconds int    `json:"utc_offset_seconds"`
+	Hourly           struct {
+		Time                     []int64    `json:"time"`
+		Temperature2m            []*float64 `json:"temperature_2m"`
+		ApparentTemperature      []*float64 `json:"apparent_temperature"`
+		PrecipitationProbability []*float64 `json:"precipitation_probability"`
+		Precipitation            []*float64 `json:"precipitation"`
+		WeatherCode              []*int     `json:"weather_code"`
+		WindSpeed10m             []*float64 `json:"wind_speed_10m"`
+		WindDirection10m         []*float64 `json:"wind_direction_10m"`
+		WindGusts10m             []*float64 `json:"wind_gusts_10m"`
+	} `json:"hourly"`
+	Daily struct {
+		Time                        []int64    `json:"time"`
+		WeatherCode                 []*int     `json:"weather_code"`
+		Temperature2mMax            []*float64 `json:"temperature_2m_max"`
+		Temperature2mMin            []*float64 `json:"temperature_2m_min"`
+		PrecipitationSum            []*float64 `json:"precipitation_sum"`
+		PrecipitationProbabilityMax []*float64 `json:"precipitation_probability_max"`
+		WindSpeed10mMax             []*float64 `json:"wind_speed_10m_max"`
+		WindGusts10mMax             []*float64 `json:"wind_gusts_10m_max"`
+		WindDirection10mDominant    []*float64 `json:"wind_direction_10m_dominant"`
+	} `json:"daily"`
+}
+
+// openMeteoHourlyVariables are the hourly forecast variables requested from Open-Meteo
+const openMeteoHourlyVariables = "temperature_2m,apparent_temperature,precipitation_probability,precipitation," +
+	"weather_code,wind_speed_10m,wind_direction_10m,wind_gusts_10m"
+
+// openMeteoDailyVariables are the daily forecast variables requested from Open-Meteo
+const openMeteoDailyVariables = "weather_code,temperature_2m_max,temperature_2m_min,precipitation_sum," +
+	"precipitation_probability_max,wind_speed_10m_max,wind_gusts_10m_max,wind_direction_10m_dominant"
+
+func (openMeteoProvider) FetchForecast(
+	ctx context.Context, config Config, location GeoResult, request ForecastRequest,
+) (*Forecast, error) {
+	span := fmt.Sprintf("forecast_hours=%d", request.Hours)
+	if request.Days > 0 {
+		// The night of the last day ends the day after
+		span = fmt.Sprintf("daily=%s&forecast_days=%d", openMeteoDailyVariables, min(request.Days+1, MaxForecastDays))
+	}
+
+	openMeteoForecast, err := fetchAndUnmarshal[OpenMeteoForecast](
+		ctx,
+		config.Client(),
+		"%s/forecast?latitude=%f&longitude=%f&hourly=%s&%s"+
+			"&timezone=auto&timeformat=unixtime&wind_speed_unit=ms&temperature_unit=celsius",
+		config.Endpoint(ProviderOpenMeteo, openMeteoEndpoint).BaseURL,
+		location.Latitude,
+		location.Longitude,
+		openMeteoHourlyVariables,
+		span,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch or decode data: %w", err)
+	}
+
+	forecast := ConvertOpenMeteoToForecast(openMeteoForecast, location.Name)
+
+	return &forecast, nil
+}
+
+// ConvertOpenMeteoToForecast converts the hourly and daily forecasts, skipping hours and days
+// missing a temperature
+func ConvertOpenMeteoToForecast(om OpenMeteoForecast, cityName string) Forecast {
+	hourly := om.Hourly
+	forecast := Forecast{
+		Hourly:   make([]ForecastStep, 0, len(hourly.Time)),
+		Timezone: &Timezone{Name: om.Timezone, OffsetSeconds: om.UTCOffsetSeconds},
+		Name:     cityName,
+	}
+
+	for i, t := range hourly.Time {
+		temp := seriesAt(hourly.Temperature2m, i)
+		if temp == nil {
+			continue
+		}
+
+		step := ForecastStep{
+			Time:      time.Unix(t, 0),
+			Duration:  time.Hour,
+			Condition: WeatherCondition{0, ConditionUnknown, "unknown weather code"},
+			Temp:      *temp,
+			FeelsLike: seriesAt(hourly.ApparentTemperature, i),
+			Wind:      Wind{Gust: seriesAt(hourly.WindGusts10m, i)},
+		}
+		if code := seriesAt(hourly.WeatherCode, i); code != nil {
+			step.Condition = WMOCondition(*code)
+		}
+		if precipitation := seriesAt(hourly.Precipitation, i); precipitation != nil {
+			step.Precipitation = *precipitation
+		}
+		if pop := seriesAt(hourly.PrecipitationProbability, i); pop != nil {
+			step.Pop = ptr(*pop / 100)
+		}
+		if speed := seriesAt(hourly.WindSpeed10m, i); speed != nil {
+			step.Wind.Speed = *speed
+		}
+		if direction := seriesAt(hourly.WindDirection10m, i); direction != nil {
+			step.Wind.Deg = int(*direction + 0.5)
+		}
+		forecast.Hourly = append(forecast.Hourly, step)
+	}
+
+	daily := om.Daily
+	location := forecast.Timezone.Location()
+	for i, t := range daily.Time {
+		tempMin, tempMax := seriesAt(daily.Temperature2mMin, i), seriesAt(daily.Temperature2mMax, i)
+		if tempMin == nil || tempMax == nil {
+			continue
+		}
+
+		start := time.Unix(t, 0).In(location)
+		day := ForecastPeriod{
+			Start:     start,
+			Duration:  start.AddDate(0, 0, 1).Sub(start),
+			Condition: WeatherCondition{0, ConditionUnknown, "unknown weather code"},
+			TempMin:   *tempMin,
+			TempMax:   *tempMax,
+			Wind:      Wind{Gust: seriesAt(daily.WindGusts10mMax, i)},
+		}
+		if code := seriesAt(daily.WeatherCode, i); code != nil {
+			day.Condition = WMOCondition(*code)
+		}
+		if precipitation := seriesAt(daily.PrecipitationSum, i); precipitation != nil {
+			day.Precipitation = *precipitation
+		}
+		if pop := seriesAt(daily.PrecipitationProbabilityMax, i); pop != nil {
+			day.Pop = ptr(*pop / 100)
+		}
+		if speed := seriesAt(daily.WindSpeed10mMax, i); speed != nil {
+			day.Wind.Speed = *speed
+		}
+		if direction := seriesAt(daily.WindDirection10mDominant, i); direction != nil {
+			day.Wind.Deg = int(*direction + 0.5)
+		}
+		forecast.Daily = append(forecast.Daily, day)
+	}
+
+	return forecast
+}
+
+// seriesAt returns the i-th value of an Open-Meteo series, nil when missing or null
+func seriesAt[T any](series []*T, i int) *T {
+	if i >= len(series) {
+		return nil
+	}
+	return series[i]
+}
@@ -0,0 +1,169 @@
+package weather
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+const openMeteoGeocodingFixture = `{"results": [
+	{"name": "Springfield", "latitude": 42.1015, "longitude": -72.5898, "country": "United States",
+		"country_code": "US", "admin1": "Massachusetts"},
+	{"name": "Springfield", "latitude": 39.8017, "longitude": -89.6437, "country": "United States",
+		"country_code": "US", "admin1": "Illinois"}
+]}`
+
+func TestOpenMeteoFetchWeather(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /search", func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("name"); got != "Springfield" {
+			t.Errorf("geocoded %q, want only the name of the query", got)
+		}
+		serveJSON(openMeteoGeocodingFixture)(w, r)
+	})
+	mux.HandleFunc("GET /forecast", func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("latitude"); !strings.HasPrefix(got, "39.8017") {
+			t.Errorf("forecast requested for latitude %s, want the Illinois match", got)
+		}
+		serveJSON(openMeteoWeatherFixture)(w, r)
+	})
+	config := stubConfig(t, mux)
+	config.City = "Springfield, IL"
+
+	weather, err := FetchWeather(context.Background(), config)
+	if err != nil {
+		t.Fatalf("fetching failed: %v", err)
+	}
+
+	if weather.Provider != ProviderOpenMeteo || weather.Name != "Springfield" {
+		t.Errorf("got %s from %s, want Springfield from %s", weather.Name, weather.Provider, ProviderOpenMeteo)
+	}
+	if weather.Main.Temp != 12.5 || weather.Main.Humidity != 80 {
+		t.Errorf("got %v °C and %d%%, want 12.5 °C and 80%%", weather.Main.Temp, weather.Main.Humidity)
+	}
+	if got := weather.Weather[0]; got.Main != ConditionRain || got.Description != "slight rain" {
+		t.Errorf("got condition %+v, want slight rain", got)
+	}
+	if weather.Main.Pressure == nil || *weather.Main.Pressure != 1008.2 {
+		t.Errorf("got pressure %v, want 1008.2 hPa", weather.Main.Pressure)
+	}
+	if weather.Visibility == nil || *weather.Visibility != 24140 {
+		t.Errorf("got visibility %v, want 24140 m", weather.Visibility)
+	}
+	if weather.Wind.Gust != nil || weather.Main.TempMin != nil {
+		t.Errorf("got gust %v and minimum %v, want the null values left out", weather.Wind.Gust, weather.Main.TempMin)
+	}
+	if weather.Main.TempMax == nil || *weather.Main.TempMax != 15 {
+		t.Errorf("got maximum %v, want 15 °C", weather.Main.TempMax)
+	}
+	if weather.Sunrise == nil || !weather.Sunrise.Equal(time.Unix(1773490000, 0)) {
+		t.Errorf("got sunrise %v, want %v", weather.Sunrise, time.Unix(1773490000, 0))
+	}
+}
+
+func TestWMOCondition(t *testing.T) {
+	tests := []struct {
+		code int
+		want WeatherCondition
+	}{
+		{0, WeatherCondition{800, ConditionClear, "clear sky"}},
+		{48, WeatherCondition{741, ConditionFog, "depositing rime fog"}},
+		{66, WeatherCondition{511, ConditionRain, "light freezing rain"}},
+		{86, WeatherCondition{622, ConditionSnow, "heavy snow showers"}},
+		{99, WeatherCondition{202, ConditionThunderstorm, "thunderstorm with heavy hail"}},
+		{42, WeatherCondition{0, ConditionUnknown, "unknown weather code"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want.Description, func(t *testing.T) {
+			if got := WMOCondition(tt.code); got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	// Every known code is described in lower case and has an icon
+	for code, condition := range wmoConditions {
+		if condition.Description != strings.ToLower(condition.Description) {
+			t.Errorf("code %d is described as %q, want lower case", code, condition.Description)
+		}
+		if icon := weatherIconName(condition.Main, condition.ID); icon == ConditionUnknown {
+			t.Errorf("code %d has no icon", code)
+		}
+	}
+}
+
+func TestConvertOpenMeteoToForecast(t *testing.T) {
+	fixture := decodeFixture[OpenMeteoForecast](t, `{
+		"timezone": "Europe/Berlin", "utc_offset_seconds": 3600,
+		"hourly": {
+			"time": [1773500400, 1773504000, 1773507600],
+			"temperature_2m": [4.5, null, 3.9],
+			"apparent_temperature": [2.0, 1.5, null],
+			"precipitation_probability": [10, 20, null],
+			"precipitation": [0.0, 0.1, 0.3],
+			"weather_code": [3, 61, null],
+			"wind_speed_10m": [5.0, 5.5, 6.0],
+			"wind_direction_10m": [269.6, 270.0, 281.2],
+			"wind_gusts_10m": [9.0, null, 11.0]
+		}
+	}`)
+
+	forecast := ConvertOpenMeteoToForecast(fixture, "Berlin")
+
+	tests := []struct {
+		name      string
+		step      ForecastStep
+		want      ForecastStep
+		wantPop   *float64
+		wantGust  *float64
+		wantFeels *float64
+	}{
+		{
+			"complete hour",
+			forecast.Hourly[0],
+			ForecastStep{Time: time.Unix(1773500400, 0), Duration: time.Hour, Condition: WMOCondition(3), Temp: 4.5,
+				Wind: Wind{Speed: 5, Deg: 270}},
+			ptr(0.1), ptr(9.0), ptr(2.0),
+		},
+		{
+			"missing values",
+			forecast.Hourly[1],
+			ForecastStep{Time: time.Unix(1773507600, 0), Duration: time.Hour,
+				Condition: WeatherCondition{0, ConditionUnknown, "unknown weather code"}, Temp: 3.9, Precipitation: 0.3, Wind: Wind{Speed: 6, Deg: 281}},
+			nil, ptr(11.0), nil,
+		},
+	}
+
+	if len(forecast.Hourly) != len(tests) {
+		t.Fatalf("got %d steps, want the hour without a temperature skipped", len(forecast.Hourly))
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step := tt.step
+			if !equalFloatPtr(step.Pop, tt.wantPop) || !equalFloatPtr(step.Wind.Gust, tt.wantGust) ||
+				!equalFloatPtr(step.FeelsLike, tt.wantFeels) {
+				t.Errorf("got probability %v, gust %v and feels like %v, want %v, %v and %v",
+					step.Pop, step.Wind.Gust, step.FeelsLike, tt.wantPop, tt.wantGust, tt.wantFeels)
+			}
+			step.Pop, step.Wind.Gust, step.FeelsLike = nil, nil, nil
+			if !step.Time.Equal(tt.want.Time) {
+				t.Errorf("got step at %v, want %v", step.Time, tt.want.Time)
+			}
+			step.Time = tt.want.Time
+			if step != tt.want {
+				t.Errorf("got %+v, want %+v", step, tt.want)
+			}
+		})
+	}
+}
+
+// equalFloatPtr reports whether two optional values are both missing or equal
+func equalFloatPtr(a, b *float64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -0,0 +1,282 @@
+package weather
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+const ProviderOpenWeatherMap = "OpenWeatherMap"
+
+var openWeatherMapEndpoint = Endpoint{
+	BaseURL:      "https://api.openweathermap.org/data/2.5",
+	GeocodingURL: "https://api.openweathermap.org/geo/1.0",
+}
+
+func init() {
+	RegisterProvider(openWeatherMapProvider{})
+}
+
+type OpenWeatherMapGeolocationResult struct {
+	City       string            `json:"name"`
+	LocalNames map[string]string `json:"local_names"`
+	Latitude   float64           `json:"lat"`
+	Longitude  float64           `json:"lon"`
+	Country    string            `json:"country"`
+	State      string            `json:"state"`
+}
+
+type OpenWeatherMapWeather struct {
+	Coordinates struct {
+		Lon float64 `json:"lon"`
+		Lat float64 `json:"lat"`
+	} `json:"coord"`
+	Weather []struct {
+		ID          int    `json:"id"`
+		Main        string `json:"main"`
+		Description string `json:"description"`
+	} `json:"weather"`
+	Base string `json:"base"`
+	Main struct {
+		Temperature          float64 `json:"temp"`
+		FeelsLikeTemperature float64 `json:"feels_like"`
+		TempMin              float64 `json:"temp_min"`
+		TempMax              float64 `json:"temp_max"`
+		Pressure             int     `json:"pressure"`
+		Humidity             int     `json:"humidity"`
+		SeaLevelPressure     int     `json:"sea_level"`
+		GroundLevelPressure  int     `json:"grnd_level"`
+	} `json:"main"`
+	VisibilityDistance *int `json:"visibility"`
+	Wind               struct {
+		Speed   float64  `json:"speed"`
+		Degrees int      `json:"deg"`
+		Gust    *float64 `json:"gust"`
+	} `json:"wind"`
+	Clouds struct {
+		All int `json:"all"`
+	} `json:"clouds"`
+	Rain struct {
+		Precipitations float64 `json:"1h,omitempty"`
+	} `json:"rain,omitempty"`
+	Snow struct {
+		Precipitations float64 `json:"1h,omitempty"`
+	} `json:"snow,omitempty"`
+	CalculationDate int `json:"dt"`
+	Sys             struct {
+		Type        int    `json:"type"`
+		Id          int    `json:"id"`
+		Country     string `json:"country"`
+		SunriseTime int    `json:"sunrise"`
+		SunsetTime  int    `json:"sunset"`
+	} `json:"sys"`
+	TimezoneShift int    `json:"timezone"`
+	ID            int    `json:"id"`
+	City          string `json:"name"`
+	Cod           int    `json:"cod"`
+}
+
+// openWeatherMapProvider queries the OpenWeatherMap geocoding and current weather APIs
+type openWeatherMapProvider struct{}
+
+func (openWeatherMapProvider) Name() string {
+	return ProviderOpenWeatherMap
+}
+
+func (openWeatherMapProvider) Capabilities() Capabilities {
+	return Capabilities{RequiresAPIKey: true}
+}
+
+func (openWeatherMapProvider) Geocode(ctx context.Context, config Config, query string) ([]GeoResult, error) {
+	// URL encode the city parameter
+	encodedCity := url.QueryEscape(query)
+
+	geoResult, err := fetchAndUnmarshal[[]OpenWeatherMapGeolocationResult](
+		ctx,
+		config.Client(),
+		"%s/direct?q=%s&limit=5&appid=%s",
+		config.Endpoint(ProviderOpenWeatherMap, openWeatherMapEndpoint).GeocodingURL,
+		encodedCity,
+		config.ApiKey,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch or decode data for geocoding: %w", err)
+	}
+
+	locations := make([]GeoResult, 0, len(geoResult))
+	for _, result := range geoResult {
+		locations = append(locations, GeoResult{
+			Name:        result.City,
+			Latitude:    result.Latitude,
+			Longitude:   result.Longitude,
+			CountryCode: result.Country,
+			Admin1:      result.State,
+		})
+	}
+
+	return locations, nil
+}
+
+func (openWeatherMapProvider) FetchCurrent(ctx context.Context, config Config, location GeoResult) (*Weather, error) {
+	openWeatherMapWeather, err := fetchAndUnmarshal[OpenWeatherMapWeather](
+		ctx,
+		config.Client(),
+		"%s/weather?lat=%f&lon=%f&units=metric&appid=%s",
+		config.Endpoint(ProviderOpenWeatherMap, openWeatherMapEndpoint).BaseURL,
+		location.Latitude,
+		location.Longitude,
+		config.ApiKey,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch or decode data: %w", err)
+	}
+
+	weather := ConvertOpenWeatherMapToWeather(openWeatherMapWeather, location.Name)
+
+	return &weather, nil
+}
+
+func ConvertOpenWeatherMapToWeather(om OpenWeatherMapWeather, cityName string) Weather {
+	// The sea level pressure is only reported separately by some stations
+	pressure := om.Main.Pressure
+	if om.Main.SeaLevelPressure > 0 {
+		pressure = om.Main.SeaLevelPressure
+	}
+
+	weather := Weather{
+		Weather: make([]WeatherCondition, len(om.Weather)),
+		Main: Measurements{
+			Temp:      om.Main.Temperature,
+			Humidity:  om.Main.Humidity,
+			FeelsLike: ptr(om.Main.FeelsLikeTemperature),
+			TempMin:   ptr(om.Main.TempMin),
+			TempMax:   ptr(om.Main.TempMax),
+		},
+		Wind: Wind{
+			Speed: om.Wind.Speed,
+			Deg:   om.Wind.Degrees,
+			Gust:  om.Wind.Gust,
+		},
+		Rain: Precipitation{
+			// Snow is reported as its water equivalent
+			OneHour: om.Rain.Precipitations + om.Snow.Precipitations,
+		},
+		CloudCover: ptr(om.Clouds.All),
+		Pop:        0, // Not provided (anymore) by OpenWeatherMap
+		Timezone:   &Timezone{OffsetSeconds: om.TimezoneShift},
+		Name:       cityName,
+		ObservedAt: time.Unix(int64(om.CalculationDate), 0),
+	}
+
+	for i, condition := range om.Weather {
+		weather.Weather[i] = WeatherCondition(condition)
+	}
+	if pressure > 0 {
+		weather.Main.Pressure = ptr(float64(pressure))
+	}
+	if om.VisibilityDistance != nil {
+		weather.Visibility = ptr(float64(*om.VisibilityDistance))
+	}
+	if om.Sys.SunriseTime > 0 && om.Sys.SunsetTime > 0 {
+		weather.Sunrise = ptr(time.Unix(int64(om.Sys.SunriseTime), 0))
+		weather.Sunset = ptr(time.Unix(int64(om.Sys.SunsetTime), 0))
+	}
+
+	return weather
+}
+
+// The 5 day forecast is made of at most 40 steps of 3 hours
+const (
+	openWeatherMapForecastStep     = 3 * time.Hour
+	openWeatherMapForecastMaxSteps = 40
+)
+
+type OpenWeatherMapForecast struct {
+	List []struct {
+		Time int64 `json:"dt"`
+		Main struct {
+			Temperature          float64 `json:"temp"`
+			FeelsLikeTemperature float64 `json:"feels_like"`
+		} `json:"main"`
+		Weather []struct {
+			ID          int    `json:"id"`
+			Main        string `json:"main"`
+			Description string `json:"description"`
+		} `json:"weather"`
+		Wind struct {
+			Speed   float64  `json:"speed"`
+			Degrees int      `json:"deg"`
+			Gust    *float64 `json:"gust"`
+		} `json:"wind"`
+		Pop  float64 `json:"pop"`
+		Rain struct {
+			Precipitations float64 `json:"3h"`
+		} `json:"rain"`
+		Snow struct {
+			Precipitations float64 `json:"3h"`
+		} `json:"snow"`
+	} `json:"list"`
+	City struct {
+		TimezoneShift int `json:"timezone"`
+	} `json:"city"`
+}
+
+func (openWeatherMapProvider) FetchForecast(
+	ctx context.Context, config Config, location GeoResult, request ForecastRequest,
+) (*Forecast, error) {
+	// One more step than needed covers the hours before the first one starts
+	steps := min((request.Hours+2)/3+1, openWeatherMapForecastMaxSteps)
+	if request.Days > 0 {
+		steps = openWeatherMapForecastMaxSteps
+	}
+	openWeatherMapForecast, err := fetchAndUnmarshal[OpenWeatherMapForecast](
+		ctx,
+		config.Client(),
+		"%s/forecast?lat=%f&lon=%f&units=metric&cnt=%d&appid=%s",
+		config.Endpoint(ProviderOpenWeatherMap, openWeatherMapEndpoint).BaseURL,
+		location.Latitude,
+		location.Longitude,
+		steps,
+		config.ApiKey,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch or decode data: %w", err)
+	}
+
+	forecast := ConvertOpenWeatherMapToForecast(openWeatherMapForecast, location.Name)
+
+	return &forecast, nil
+}
+
+func ConvertOpenWeatherMapToForecast(om OpenWeatherMapForecast, cityName string) Forecast {
+	forecast := Forecast{
+		Hourly:   make([]ForecastStep, len(om.List)),
+		Timezone: &Timezone{OffsetSeconds: om.City.TimezoneShift},
+		Name:     cityName,
+	}
+
+	for i, item := range om.List {
+		step := ForecastStep{
+			Time:      time.Unix(item.Time, 0),
+			Duration:  openWeatherMapForecastStep,
+			Condition: WeatherCondition{0, ConditionUnknown, "unknown conditions"},
+			Temp:      item.Main.Temperature,
+			FeelsLike: ptr(item.Main.FeelsLikeTemperature),
+			// Snow is reported as its water equivalent
+			Precipitation: item.Rain.Precipitations + item.Snow.Precipitations,
+			Pop:           ptr(item.Pop),
+			Wind: Wind{
+				Speed: item.Wind.Speed,
+				Deg:   item.Wind.Degrees,
+				Gust:  item.Wind.Gust,
+			},
+		}
+		if len(item.Weather) > 0 {
+			step.Condition = WeatherCondition(item.Weather[0])
+		}
+		forecast.Hourly[i] = step
+	}
+
+	return forecast
+}
@@ -0,0 +1,109 @@
+package weather
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestOpenWeatherMapFetchWeather(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /weather", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("appid") != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = io.WriteString(w, `{"cod": 401, "message": "Invalid API key."}`)
+			return
+		}
+		serveJSON(`{
+			"weather": [{"id": 500, "main": "Rain", "description": "light rain"}],
+			"main": {"temp": 8.2, "feels_like": 6.1, "temp_min": 7.0, "temp_max": 9.5, "pressure": 1012,
+				"humidity": 87, "sea_level": 1013},
+			"visibility": 9000,
+			"wind": {"speed": 4.1, "deg": 230, "gust": 7.2},
+			"clouds": {"all": 90},
+			"rain": {"1h": 0.6},
+			"snow": {"1h": 0.2},
+			"dt": 1773500000,
+			"sys": {"sunrise": 1773490000, "sunset": 1773533000},
+			"timezone": 3600,
+			"name": "London"
+		}`)(w, r)
+	})
+	config := stubConfig(t, mux)
+	config.Provider = ProviderOpenWeatherMap
+	config.Latitude, config.Longitude = ptr(51.5072), ptr(-0.1276)
+	config.Label = "London"
+
+	t.Run("invalid API key", func(t *testing.T) {
+		config := config
+		config.ApiKey = "wrong"
+		if _, err := FetchWeather(context.Background(), config); !errors.Is(err, ErrInvalidAPIKey) {
+			t.Errorf("got error %v, want %v", err, ErrInvalidAPIKey)
+		}
+	})
+
+	t.Run("missing API key", func(t *testing.T) {
+		if _, err := FetchWeather(context.Background(), config); !errors.Is(err, ErrMissingAPIKey) {
+			t.Errorf("got error %v, want %v", err, ErrMissingAPIKey)
+		}
+	})
+
+	t.Run("conversion", func(t *testing.T) {
+		config := config
+		config.ApiKey = "secret"
+		weather, err := FetchWeather(context.Background(), config)
+		if err != nil {
+			t.Fatalf("fetching failed: %v", err)
+		}
+
+		if weather.Main.Temp != 8.2 || weather.Name != "London" {
+			t.Errorf("got %v °C in %s, want 8.2 °C in London", weather.Main.Temp, weather.Name)
+		}
+		if weather.Main.Pressure == nil || *weather.Main.Pressure != 1013 {
+			t.Errorf("got pressure %v, want the sea level pressure of 1013 hPa", weather.Main.Pressure)
+		}
+		if weather.Rain.OneHour != 0.8 {
+			t.Errorf("got %v mm, want rain and snow added up to 0.8 mm", weather.Rain.OneHour)
+		}
+		if weather.Visibility == nil || *weather.Visibility != 9000 {
+			t.Errorf("got visibility %v, want 9000 m", weather.Visibility)
+		}
+		if weather.Weather[0].Description != "light rain" {
+			t.Errorf("got description %q, want %q", weather.Weather[0].Description, "light rain")
+		}
+	})
+}
+
+func TestConvertOpenWeatherMapToForecast(t *testing.T) {
+	fixture := decodeFixture[OpenWeatherMapForecast](t, `{
+		"list": [
+			{"dt": 1773500400, "main": {"temp": 7.5, "feels_like": 5.0},
+				"weather": [{"id": 803, "main": "Clouds", "description": "broken clouds"}],
+				"wind": {"speed": 3.2, "deg": 180}, "pop": 0.2},
+			{"dt": 1773511200, "main": {"temp": 6.0, "feels_like": 3.5}, "weather": [],
+				"wind": {"speed": 4.0, "deg": 190, "gust": 8.5}, "pop": 0.75,
+				"rain": {"3h": 1.2}, "snow": {"3h": 0.3}}
+		],
+		"city": {"timezone": 3600}
+	}`)
+
+	forecast := ConvertOpenWeatherMapToForecast(fixture, "London")
+
+	if len(forecast.Hourly) != 2 {
+		t.Fatalf("got %d steps, want 2", len(forecast.Hourly))
+	}
+	if forecast.Timezone == nil || forecast.Timezone.OffsetSeconds != 3600 {
+		t.Errorf("got timezone %+v, want an offset of 3600 s", forecast.Timezone)
+	}
+
+	first, second := forecast.Hourly[0], forecast.Hourly[1]
+	if first.Duration != 3*time.Hour || first.Condition.Description != "broken clouds" || *first.Pop != 0.2 {
+		t.Errorf("got first step %+v, want 3 hours of broken clouds", first)
+	}
+	if second.Condition.Main != ConditionUnknown || second.Precipitation != 1.5 || *second.Wind.Gust != 8.5 {
+		t.Errorf("got second step %+v, want unknown conditions with 1.5 mm", second)
+	}
+}
@@ -0,0 +1,259 @@
+package weather
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Provider is a weather backend able to resolve locations and report current conditions.
+// New backends implement this interface in their own file and register themselves with
+// RegisterProvider from an init function.
+type Provider interface {
+	// Name returns the identifier used to select the provider in the configuration
+	Name() string
+	// Capabilities describes what the provider supports and requires
+	Capabilities() Capabilities
+	// Geocode resolves a location query into candidate locations, best match first
+	Geocode(ctx context.Context, config Config, query string) ([]GeoResult, error)
+	// FetchCurrent fetches the current weather for a resolved location
+	FetchCurrent(ctx context.Context, config Config, location GeoResult) (*Weather, error)
+}
+
+// Capabilities describes the features and requirements of a provider
+type Capabilities struct {
+	// RequiresAPIKey is set when the provider can't be queried without config.ApiKey
+	RequiresAPIKey bool
+}
+
+var registry = map[string]Provider{}
+
+// RegisterProvider makes a provider selectable by its name.
+// It panics if a provider with the same name is already registered.
+func RegisterProvider(provider Provider) {
+	name := provider.Name()
+	if _, exists := registry[name]; exists {
+		panic(fmt.Sprintf("weather: provider %q registered twice", name))
+	}
+	registry[name] = provider
+}
+
+// LookupProvider returns the registered provider with the given name
+func LookupProvider(name string) (Provider, bool) {
+	provider, ok := registry[name]
+	return provider, ok
+}
+
+// ProviderNames returns the names of all registered providers in sorted order
+func ProviderNames() []string {
+	return slices.Sorted(maps.Keys(registry))
+}
+
+// FetchWeather fetches weather data from the configured providers, falling through to
+// the next provider in the chain whenever one fails. In ensemble mode all providers are
+// queried and their readings merged instead. When the network is unavailable, or in
+// offline mode, the last reading stored for the location is returned.
+func FetchWeather(ctx context.Context, config Config) (*Weather, error) {
+	if config.Offline {
+		return LastKnownWeather(config)
+	}
+
+	var weather *Weather
+	var err error
+	if config.Ensemble {
+		weather, err = FetchEnsemble(ctx, config)
+	} else {
+		weather, err = fetchWeatherChain(ctx, config)
+	}
+
+	if err != nil {
+		if IsNetworkError(err) {
+			if last, lastErr := LastKnownWeather(config); lastErr == nil {
+				Logger.InfoContext(ctx, "network unavailable, using the last known weather",
+					"fetched_at", last.FetchedAt, "error", err)
+				return last, nil
+			}
+		}
+		return nil, err
+	}
+
+	// Failing to remember the reading only affects offline use, so it isn't fatal
+	storeCache(ctx, "last", lastKnownKey(config), *weather)
+
+	return weather, nil
+}
+
+// LastKnownWeather returns the most recent reading stored for the configured location,
+// marked as offline
+func LastKnownWeather(config Config) (*Weather, error) {
+	weather, _, ok := readCache[Weather]("last", lastKnownKey(config))
+	if !ok {
+		return nil, fmt.Errorf("no weather data stored for %s yet", lastKnownKey(config))
+	}
+
+	weather.Offline = true
+	return &weather, nil
+}
+
+// lastKnownKey identifies the configured location regardless of the provider. Coordinates
+// picked for the city are keyed by the city, which offline runs skipping the picker look up.
+func lastKnownKey(config Config) string {
+	if latitude, longitude, ok := config.Coordinates(); ok && config.ResolvedCity == "" {
+		return fmt.Sprintf("%.4f,%.4f", latitude, longitude)
+	}
+	return normalizeQuery(config.City)
+}
+
+// IsNetworkError reports whether the error comes from failing to reach a provider,
+// as opposed to the provider answering with an error
+func IsNetworkError(err error) bool {
+	var netErr *NetworkError
+	return errors.As(err, &netErr)
+}
+
+// fetchWeatherChain tries the providers of the chain in order until one answers
+func fetchWeatherChain(ctx context.Context, config Config) (*Weather, error) {
+	chain := config.ProviderChain()
+	errs := make([]error, 0, len(chain))
+
+	for _, name := range chain {
+		weather, err := fetchWeatherFrom(ctx, name, config)
+		if err == nil {
+			Logger.InfoContext(ctx, "provider chosen", "provider", name)
+			weather.Provider = name
+			return weather, nil
+		}
+		Logger.InfoContext(ctx, "provider failed", "provider", name, "error", err)
+
+		if len(chain) == 1 {
+			return nil, err
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", name, err))
+	}
+
+	return nil, errors.Join(errs...)
+}
+
+// fetchWeatherFrom resolves the configured city and fetches its weather using a single provider
+func fetchWeatherFrom(ctx context.Context, name string, config Config) (*Weather, error) {
+	provider, ok := LookupProvider(name)
+	if !ok {
+		return nil, fmt.Errorf("unknown provider %q", name)
+	}
+	if provider.Capabilities().RequiresAPIKey && config.ApiKey == "" {
+		return nil, fmt.Errorf("%w for %s", ErrMissingAPIKey, name)
+	}
+
+	location, err := resolveLocation(ctx, provider, config)
+	if err != nil {
+		return nil, err
+	}
+
+	// Serve a recent enough reading without touching the network
+	key := fmt.Sprintf("%s|%.4f,%.4f", name, location.Latitude, location.Longitude)
+	maxAge := config.CacheMaxAge.Duration
+	if maxAge > 0 {
+		if cached, _, ok := readCache[Weather]("weather", key); ok && time.Since(cached.FetchedAt) < maxAge {
+			Logger.InfoContext(ctx, "weather cache hit",
+				"provider", name, "age", time.Since(cached.FetchedAt).Round(time.Second))
+			return &cached, nil
+		}
+		Logger.DebugContext(ctx, "weather cache miss", "provider", name, "key", key)
+	}
+
+	weather, err := provider.FetchCurrent(ctx, config, *location)
+	if err != nil {
+		return nil, err
+	}
+	weather.FetchedAt = time.Now()
+
+	if maxAge > 0 {
+		storeCache(ctx, "weather", key, *weather)
+	}
+
+	return weather, nil
+}
+
+// SearchLocations geocodes the configured city with the primary provider and returns
+// every candidate location, best match first
+func SearchLocations(ctx context.Context, config Config) ([]GeoResult, error) {
+	name := config.ProviderChain()[0]
+	provider, ok := LookupProvider(name)
+	if !ok {
+		return nil, fmt.Errorf("unknown provider %q", name)
+	}
+
+	return geocodeCached(ctx, provider, config, config.City)
+}
+
+// resolveLocation returns the configured coordinates, geocoding the city only when none are set
+func resolveLocation(ctx context.Context, provider Provider, config Config) (*GeoResult, error) {
+	if latitude, longitude, ok := config.Coordinates(); ok {
+		name := config.Label
+		if name == "" {
+			name = fmt.Sprintf("%.4f, %.4f", latitude, longitude)
+		}
+		Logger.DebugContext(ctx, "using configured coordinates", "latitude", latitude, "longitude", longitude)
+		return &GeoResult{Name: name, Latitude: latitude, Longitude: longitude}, nil
+	}
+
+	locations, err := geocodeCached(ctx, provider, config, config.City)
+	if err != nil {
+		return nil, err
+	}
+	if len(locations) == 0 {
+		return nil, fmt.Errorf("no results found for city %s: %w", config.City, ErrLocationNotFound)
+	}
+
+	for i, candidate := range locations[1:] {
+		Logger.DebugContext(ctx, "location candidate", "rank", i+2, "name", candidate.DisplayName(),
+			"latitude", candidate.Latitude, "longitude", candidate.Longitude, "population", candidate.Population)
+	}
+	location := locations[0]
+	Logger.InfoContext(ctx, "location resolved", "query", config.City, "provider", provider.Name(),
+		"name", location.DisplayName(), "latitude", location.Latitude, "longitude", location.Longitude,
+		"candidates", len(locations))
+
+	return &location, nil
+}
+
+// geocodeCacheTTL is how long geocoding results are reused, places rarely move
+const geocodeCacheTTL = 30 * 24 * time.Hour
+
+// geocodeCached geocodes a query through the on-disk cache, which is bypassed but
+// refreshed when config.RefreshLocation is set
+func geocodeCached(ctx context.Context, provider Provider, config Config, query string) ([]GeoResult, error) {
+	key := provider.Name() + "|" + normalizeQuery(query)
+	if !config.RefreshLocation {
+		locations, storedAt, ok := readCache[[]GeoResult]("geocode", key)
+		if ok && len(locations) > 0 && time.Since(storedAt) < geocodeCacheTTL {
+			Logger.InfoContext(ctx, "geocoding cache hit",
+				"query", query, "provider", provider.Name(), "age", time.Since(storedAt).Round(time.Second))
+			return locations, nil
+		}
+	}
+
+	locations, err := provider.Geocode(ctx, config, query)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(locations) > 0 {
+		storeCache(ctx, "geocode", key, locations)
+	}
+
+	return locations, nil
+}
+
+// normalizeQuery makes equivalent spellings of a location query share a cache entry
+func normalizeQuery(query string) string {
+	parts := strings.Split(strings.ToLower(query), ",")
+	for i, part := range parts {
+		parts[i] = strings.Join(strings.Fields(part), " ")
+	}
+	return strings.Join(parts, ",")
+}
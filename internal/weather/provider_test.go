@@ -0,0 +1,285 @@
+package weather
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// stubConfig points every provider at a stub server, with an empty cache directory
+func stubConfig(t *testing.T, handler http.Handler) Config {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	config := DefaultConfig()
+	config.Retries = 0
+	config.CacheMaxAge = Duration{}
+	config.Endpoints = make(map[string]Endpoint)
+	for _, name := range ProviderNames() {
+		config.Endpoints[name] = Endpoint{BaseURL: server.URL, GeocodingURL: server.URL}
+	}
+	return config
+}
+
+// serveJSON answers every request with the given JSON document
+func serveJSON(body string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, body)
+	}
+}
+
+// decodeFixture decodes a JSON document into the response type of a provider
+func decodeFixture[T any](t *testing.T, body string) T {
+	t.Helper()
+	var out T
+	if err := json.Unmarshal([]byte(body), &out); err != nil {
+		t.Fatalf("invalid fixture: %v", err)
+	}
+	return out
+}
+
+const openMeteoWeatherFixture = `{
+	"timezone": "America/Chicago", "utc_offset_seconds": -18000,
+	"current": {"time": 1773500000, "temperature_2m": 12.5, "apparent_temperature": 11.0,
+		"weather_code": 61, "precipitation": 0.4, "relative_humidity_2m": 80, "cloud_cover": 95,
+		"pressure_msl": 1008.2, "visibility": 24140, "wind_speed_10m": 3.5,
+		"wind_direction_10m": 200, "wind_gusts_10m": null},
+	"daily": {"sunrise": [1773490000], "sunset": [1773533000],
+		"temperature_2m_max": [15.0], "temperature_2m_min": [null]}
+}`
+
+func TestFetchWeatherChain(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /weather", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	mux.HandleFunc("GET /forecast", serveJSON(openMeteoWeatherFixture))
+	config := stubConfig(t, mux)
+	config.Providers = []string{ProviderOpenWeatherMap, ProviderOpenMeteo}
+	config.ApiKey = "secret"
+	config.Latitude, config.Longitude = ptr(39.8017), ptr(-89.6437)
+
+	weather, err := FetchWeather(context.Background(), config)
+	if err != nil {
+		t.Fatalf("fetching failed: %v", err)
+	}
+	if weather.Provider != ProviderOpenMeteo {
+		t.Errorf("got reading from %s, want the chain to fall through to %s", weather.Provider, ProviderOpenMeteo)
+	}
+
+	// Once every provider fails, the errors of all of them are reported
+	config.Providers = []string{ProviderOpenWeatherMap, ProviderNWS}
+	_, err = FetchWeather(context.Background(), config)
+	var statusErr *HTTPStatusError
+	if !errors.As(err, &statusErr) || !strings.Contains(err.Error(), ProviderNWS+":") {
+		t.Errorf("got error %v, want the errors of both providers", err)
+	}
+}
+
+func TestGeocodeCache(t *testing.T) {
+	var searches atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /search", func(w http.ResponseWriter, r *http.Request) {
+		searches.Add(1)
+		serveJSON(`{"results": [{"name": "Paris", "latitude": 48.8566, "longitude": 2.3522,
+			"country": "France", "country_code": "FR"}]}`)(w, r)
+	})
+	mux.HandleFunc("GET /forecast", serveJSON(openMeteoWeatherFixture))
+	config := stubConfig(t, mux)
+
+	tests := []struct {
+		name         string
+		city         string
+		refresh      bool
+		storedAgo    time.Duration
+		wantSearches int32
+	}{
+		{"first lookup", "Paris", false, 0, 1},
+		{"cached", "Paris", false, 0, 1},
+		{"same query spelled differently", "  PARIS ", false, 0, 1},
+		{"refreshed", "Paris", true, 0, 2},
+		{"expired", "Paris", false, geocodeCacheTTL + time.Hour, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.storedAgo > 0 {
+				key := ProviderOpenMeteo + "|" + normalizeQuery(tt.city)
+				locations, _, _ := readCache[[]GeoResult]("geocode", key)
+				data, _ := json.Marshal(cacheEntry[[]GeoResult]{StoredAt: time.Now().Add(-tt.storedAgo), Value: locations})
+				if err := os.WriteFile(cacheFilePath("geocode", key), data, 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			config := config
+			config.City, config.RefreshLocation = tt.city, tt.refresh
+			weather, err := FetchWeather(context.Background(), config)
+			if err != nil {
+				t.Fatalf("fetching failed: %v", err)
+			}
+			if weather.Name != "Paris" {
+				t.Errorf("got weather for %q, want Paris", weather.Name)
+			}
+			if got := searches.Load(); got != tt.wantSearches {
+				t.Errorf("got %d geocoding requests in total, want %d", got, tt.wantSearches)
+			}
+		})
+	}
+}
+
+func TestWeatherCache(t *testing.T) {
+	var requests atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /forecast", func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		serveJSON(openMeteoWeatherFixture)(w, r)
+	})
+	config := stubConfig(t, mux)
+	config.Latitude, config.Longitude = ptr(39.8017), ptr(-89.6437)
+	config.CacheMaxAge = Duration{10 * time.Minute}
+	key := fmt.Sprintf("%s|%.4f,%.4f", ProviderOpenMeteo, 39.8017, -89.6437)
+
+	first, err := FetchWeather(context.Background(), config)
+	if err != nil {
+		t.Fatalf("fetching failed: %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		fetchedAgo   time.Duration
+		maxAge       time.Duration
+		wantRequests int32
+		wantAge      time.Duration
+	}{
+		{"fresh", 0, 10 * time.Minute, 1, 0},
+		{"aging", 7 * time.Minute, 10 * time.Minute, 1, 7 * time.Minute},
+		{"expired", 11 * time.Minute, 10 * time.Minute, 2, 0},
+		{"disabled", 0, 0, 3, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cached := *first
+			cached.FetchedAt = time.Now().Add(-tt.fetchedAgo)
+			if err := writeCache("weather", key, cached); err != nil {
+				t.Fatal(err)
+			}
+
+			config := config
+			config.CacheMaxAge = Duration{tt.maxAge}
+			weather, err := FetchWeather(context.Background(), config)
+			if err != nil {
+				t.Fatalf("fetching failed: %v", err)
+			}
+			if got := requests.Load(); got != tt.wantRequests {
+				t.Errorf("got %d requests in total, want %d", got, tt.wantRequests)
+			}
+			// Cached readings keep the time they were fetched at, which their age is shown from
+			if age := time.Since(weather.FetchedAt); age < tt.wantAge || age > tt.wantAge+time.Minute {
+				t.Errorf("got a reading %v old, want %v", age, tt.wantAge)
+			}
+		})
+	}
+}
+
+func TestOfflineAfterOnline(t *testing.T) {
+	paris := func(config *Config) {
+		config.City = "Paris"
+	}
+	coordinates := func(config *Config) {
+		config.Latitude, config.Longitude = ptr(48.8566), ptr(2.3522)
+	}
+	picked := func(config *Config) {
+		paris(config)
+		coordinates(config)
+		config.Label, config.ResolvedCity = "Paris", "Paris"
+	}
+
+	tests := []struct {
+		name            string
+		online, offline func(*Config)
+		wantErr         bool
+	}{
+		// The picker fills in coordinates when online, offline runs skip it
+		{"picked location", picked, paris, false},
+		{"saved pick", picked, picked, false},
+		{"coordinates", coordinates, coordinates, false},
+		{"city spelled differently", paris, func(config *Config) { config.City = " paris" }, false},
+		{"other city", picked, func(config *Config) { config.City = "Lyon" }, true},
+		{"other coordinates", coordinates, func(config *Config) { config.Latitude, config.Longitude = ptr(0.0), ptr(0.0) }, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mux := http.NewServeMux()
+			mux.HandleFunc("GET /search", serveJSON(`{"results": [{"name": "Paris", "latitude": 48.8566,
+				"longitude": 2.3522, "country": "France", "country_code": "FR"}]}`))
+			mux.HandleFunc("GET /forecast", serveJSON(openMeteoWeatherFixture))
+			config := stubConfig(t, mux)
+
+			online := config
+			tt.online(&online)
+			if _, err := FetchWeather(context.Background(), online); err != nil {
+				t.Fatalf("fetching failed: %v", err)
+			}
+
+			offline := config
+			offline.Offline = true
+			tt.offline(&offline)
+			weather, err := FetchWeather(context.Background(), offline)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("found a reading stored for another location")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("no reading found offline: %v", err)
+			}
+			if !weather.Offline || weather.Main.Temp != 12.5 {
+				t.Errorf("got %v °C, offline %v, want the stored 12.5 °C marked offline", weather.Main.Temp, weather.Offline)
+			}
+		})
+	}
+}
+
+func TestLastKnownWeatherFallback(t *testing.T) {
+	server := httptest.NewServer(serveJSON(openMeteoWeatherFixture))
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	config := DefaultConfig()
+	config.Retries, config.CacheMaxAge = 0, Duration{}
+	config.Endpoints = map[string]Endpoint{ProviderOpenMeteo: {BaseURL: server.URL}}
+	config.Latitude, config.Longitude = ptr(48.8566), ptr(2.3522)
+
+	if _, err := FetchWeather(context.Background(), config); err != nil {
+		t.Fatalf("fetching failed: %v", err)
+	}
+	server.Close()
+
+	weather, err := FetchWeather(context.Background(), config)
+	if err != nil {
+		t.Fatalf("got error %v, want the last known weather", err)
+	}
+	if !weather.Offline {
+		t.Error("the last known weather isn't marked offline")
+	}
+
+	// Nothing was ever stored for another location
+	config.Latitude = ptr(40.0)
+	if _, err := FetchWeather(context.Background(), config); !IsNetworkError(err) {
+		t.Errorf("got error %v, want the network error", err)
+	}
+}
@@ -2,10 +2,13 @@ package main
 
 import (
 	"bufio"
+	"context"
 	"errors"
 	"fmt"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
@@ -18,6 +21,18 @@ import (
 // version is set during build time using -ldflags
 var version = "dev"
 
+// Exit codes, documented in the README so that scripts can tell failures apart
+const (
+	exitError            = 1 // any other error
+	exitUsage            = 2 // invalid command line flags or location query
+	exitNetwork          = 3 // no provider could be reached
+	exitInvalidAPIKey    = 4 // the API key is missing or was rejected
+	exitLocationNotFound = 5 // the location couldn't be found or isn't covered
+	exitRateLimited      = 6 // a provider refused the request because of rate limiting
+	exitProviderError    = 7 // a provider answered with an error or an invalid response
+	exitInvalidConfig    = 8 // the network settings of the config can't be used
+)
+
 func init() {
 	c := make(chan os.Signal, 1)
 	signal.Notify(c, os.Interrupt, syscall.SIGTERM, syscall.SIGQUIT)
@@ -96,8 +111,20 @@ func main() {
 		os.Exit(0)
 	}
 
-	// Read/create config
-	config := weather.ReadConfig()
+	// Trace requests and decisions to stderr when asked to
+	if logger := weather.NewLogger(os.Stderr, flags); logger != nil {
+		weather.Logger = logger
+	}
+
+	// Identify this version in requests
+	weather.UserAgent = weather.UserAgentFor(version)
+
+	// Read/create config, along with the HTTP client shared by all requests
+	config, err := weather.ReadConfig()
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Invalid network settings in config: %v\n", err)
+		os.Exit(exitInvalidConfig)
+	}
 
 	// Override config with command line flags if provided
 	preFlagsConfig := config
@@ -105,8 +132,9 @@ func main() {
 
 	scanner := bufio.NewScanner(os.Stdin)
 
-	// Check if the city is set
-	if config.City == "" {
+	// Check if the city is set, unless coordinates make it unnecessary
+	_, _, hasCoordinates := config.Coordinates()
+	if config.City == "" && !hasCoordinates {
 		fmt.Printf("No city found in your configuration, please enter the city to check the weather for: ")
 		scanner.Scan()
 		newCity := scanner.Text()
@@ -120,9 +148,11 @@ func main() {
 		}
 	}
 
-	// Check if the API key and city are set
-	if config.Provider == weather.ProviderOpenWeatherMap && config.ApiKey == "" {
-		fmt.Printf("No API key provided for %s, please enter it: ", config.Provider)
+	// Check if the API key is set for the primary provider if it requires one
+	primary := config.ProviderChain()[0]
+	provider, ok := weather.LookupProvider(primary)
+	if ok && provider.Capabilities().RequiresAPIKey && config.ApiKey == "" {
+		fmt.Printf("No API key provided for %s, please enter it: ", primary)
 		scanner.Scan()
 		apiKey := scanner.Text()
 		config.ApiKey = apiKey
@@ -135,40 +165,194 @@ func main() {
 		}
 	}
 
-	fetchAndDisplay(config, false)
+	// Let the user pick among ambiguous matches, unless coordinates are already known
+	if _, _, ok := config.Coordinates(); !ok && !config.Offline && term.IsTerminal(int(os.Stdin.Fd())) {
+		if location, asked := pickLocation(scanner, config); location != nil {
+			config.Latitude, config.Longitude = &location.Latitude, &location.Longitude
+			config.Label, config.ResolvedCity = location.Name, config.City
+
+			// Only remember an actual choice, and only for the configured city rather than one given on the command line
+			if asked && flags.City == "" {
+				preFlagsConfig.Latitude, preFlagsConfig.Longitude = config.Latitude, config.Longitude
+				preFlagsConfig.Label, preFlagsConfig.ResolvedCity = config.Label, config.ResolvedCity
+				err := weather.WriteConfig(preFlagsConfig, weather.GetConfigPath())
+				if err != nil {
+					_, _ = fmt.Fprintf(os.Stderr, "Failed to update your stored config: %v\n", err)
+					_, _ = fmt.Fprintln(os.Stderr, "You'll need to pick your location again next time")
+				}
+			}
+		}
+	}
+
+	var show view = currentWeather
+	if flags.Hourly > 0 {
+		show = hourlyForecast(flags.Hourly)
+	} else if flags.Days > 0 {
+		show = dailyForecast(flags.Days)
+	}
+	fetchAndDisplay(config, show, 0)
 }
 
-// fetchAndDisplay fetches weather data and displays it according to the given configuration.
-// clearDisplay determines whether the screen should be cleared before displaying updated information.
-func fetchAndDisplay(config weather.Config, clearDisplay bool) {
-	// Fetch weather data
-	weatherData, err := weather.FetchWeather(config)
+// view fetches what to show and returns the function displaying it, which returns the
+// number of printed lines
+type view func(ctx context.Context, config weather.Config) (display func() int, err error)
+
+// currentWeather shows the current weather, along with the temperature trend when enabled
+func currentWeather(ctx context.Context, config weather.Config) (func() int, error) {
+	weatherData, err := weather.FetchWeather(ctx, config)
 	if err != nil {
+		return nil, err
+	}
+
+	// The trend is a nicety, so the current weather is shown without it when it can't be fetched
+	var outlook *weather.Forecast
+	if config.ShowTrend && !weatherData.Offline {
+		outlook, _ = weather.FetchForecast(ctx, config, weather.ForecastRequest{Hours: weather.DefaultForecastHours})
+	}
+
+	return func() int { return weather.DisplayWeather(weatherData, outlook, config) }, nil
+}
+
+// hourlyForecast shows the forecast for the given number of hours
+func hourlyForecast(hours int) view {
+	return func(ctx context.Context, config weather.Config) (func() int, error) {
+		forecast, err := weather.FetchForecast(ctx, config, weather.ForecastRequest{Hours: hours})
+		if err != nil {
+			return nil, err
+		}
+		return func() int { return weather.DisplayHourlyForecast(forecast, config) }, nil
+	}
+}
+
+// dailyForecast shows the forecast for the given number of days
+func dailyForecast(days int) view {
+	return func(ctx context.Context, config weather.Config) (func() int, error) {
+		forecast, err := weather.FetchForecast(ctx, config, weather.ForecastRequest{Days: days})
+		if err != nil {
+			return nil, err
+		}
+		return func() int { return weather.DisplayDailyForecast(forecast, config) }, nil
+	}
+}
+
+// pickLocation geocodes the configured city and, when several places match, asks the user
+// which one they mean. asked reports whether the user had to choose. The location is nil
+// when it couldn't be resolved, in which case fetching the weather will report the error.
+func pickLocation(scanner *bufio.Scanner, config weather.Config) (location *weather.GeoResult, asked bool) {
+	candidates, err := weather.SearchLocations(context.Background(), config)
+	if err != nil || len(candidates) == 0 {
+		return nil, false
+	}
+	if len(candidates) == 1 {
+		return &candidates[0], false
+	}
+
+	fmt.Printf("Several places match \"%s\":\n", config.City)
+	for i, candidate := range candidates {
+		details := fmt.Sprintf("%.4f, %.4f", candidate.Latitude, candidate.Longitude)
+		if candidate.Population > 0 {
+			details = fmt.Sprintf("pop. %s, %s", formatThousands(candidate.Population), details)
+		}
+		fmt.Printf("%3d) %s (%s)\n", i+1, candidate.DisplayName(), details)
+	}
+
+	for {
+		fmt.Printf("Choose a location [1-%d] (default 1): ", len(candidates))
+		if !scanner.Scan() {
+			return &candidates[0], false
+		}
+
+		choice := strings.TrimSpace(scanner.Text())
+		if choice == "" {
+			return &candidates[0], true
+		}
+		if n, err := strconv.Atoi(choice); err == nil && n >= 1 && n <= len(candidates) {
+			return &candidates[n-1], true
+		}
+		fmt.Printf("Please enter a number between 1 and %d.\n", len(candidates))
+	}
+}
+
+// formatThousands formats a number with comma thousands separators
+func formatThousands(n int) string {
+	digits := strconv.Itoa(n)
+	var b strings.Builder
+	for i, digit := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(digit)
+	}
+	return b.String()
+}
+
+// exitCode maps an error to the exit code documenting its cause. When several providers
+// failed, the most actionable cause wins.
+func exitCode(err error) int {
+	var statusErr *weather.HTTPStatusError
+	var decodeErr *weather.DecodeError
+	switch {
+	case errors.Is(err, weather.ErrUnsupportedQuery):
+		return exitUsage
+	case errors.Is(err, weather.ErrInvalidAPIKey), errors.Is(err, weather.ErrMissingAPIKey):
+		return exitInvalidAPIKey
+	case errors.Is(err, weather.ErrLocationNotFound):
+		return exitLocationNotFound
+	case errors.Is(err, weather.ErrRateLimited):
+		return exitRateLimited
+	case weather.IsNetworkError(err):
+		return exitNetwork
+	case errors.As(err, &statusErr), errors.As(err, &decodeErr):
+		return exitProviderError
+	default:
+		return exitError
+	}
+}
+
+// fetchAndDisplay fetches weather data and displays it with the given view according to the given configuration.
+// clearLines is the number of previously displayed lines to clear before displaying updated information.
+func fetchAndDisplay(config weather.Config, show view, clearLines int) {
+	// Fetch weather data
+	display, err := show(context.Background(), config)
+	if err != nil && clearLines == 0 {
 		_, _ = fmt.Fprintf(os.Stderr, "Failed to fetch weather data: %v\n", err)
-		if errors.Is(err, weather.ErrUnsupportedQuery) {
-			_, _ = fmt.Fprintf(
-				os.Stderr, "Detailed queries are not supported by %s, try using other providers.\n",
-				weather.ProviderOpenMeteo,
+		code := exitCode(err)
+		switch code {
+		case exitUsage:
+			_, _ = fmt.Fprintln(
+				os.Stderr, "Use \"city\", \"city, region\" or \"city, region, country\", e.g. \"Springfield, IL, US\".",
 			)
-		} else {
-			_, _ = fmt.Fprintln(os.Stderr, "Please check your internet connection and API key.")
+		case exitNetwork:
+			_, _ = fmt.Fprintln(os.Stderr, "Please check your internet connection.")
+		case exitInvalidAPIKey:
+			_, _ = fmt.Fprintf(os.Stderr, "Please check the API key in %s.\n", weather.GetConfigPath())
+		case exitLocationNotFound:
+			_, _ = fmt.Fprintln(os.Stderr, "Please check the city name or coordinates.")
+		case exitRateLimited:
+			_, _ = fmt.Fprintln(os.Stderr, "Too many requests, please try again later.")
 		}
-		os.Exit(1)
+		os.Exit(code)
 	}
 
-	// Clear screen in live mode
-	if clearDisplay {
-		_, _ = ansi.Printf("\x1b[%dA\x1b[J", 7) // maximum number of displayed lines
-	}
+	lines := clearLines
+	if err != nil {
+		// Only the first fetch is fatal in live mode, a failed refresh keeps the previous display until the next one
+		weather.Logger.Warn("refresh failed, keeping the previous display", "error", err)
+	} else {
+		// Clear screen in live mode
+		if clearLines > 0 {
+			_, _ = ansi.Printf("\x1b[%dA\x1b[J", clearLines)
+		}
 
-	// Display the weather
-	weather.DisplayWeather(weatherData, config)
+		// Display the weather
+		lines = display()
+	}
 
 	// Loop in live mode
 	if !config.LiveMode {
 		return
 	}
-	if !clearDisplay {
+	if clearLines == 0 {
 		// hide cursor on live mode startup
 		_, _ = ansi.Print("\x1b[?25l")
 	}
@@ -177,5 +361,5 @@ func fetchAndDisplay(config weather.Config, clearDisplay bool) {
 	go listenForQuit(stop)
 	time.Sleep(15 * time.Second)
 	stop <- struct{}{}
-	fetchAndDisplay(config, true)
+	fetchAndDisplay(config, show, lines)
 }
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/ashish0kumar/stormy/internal/weather"
+)
+
+func TestExitCode(t *testing.T) {
+	network := &weather.NetworkError{Err: io.ErrUnexpectedEOF}
+
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"unsupported query", fmt.Errorf("geocoding failed: %w", weather.ErrUnsupportedQuery), exitUsage},
+		{"rejected API key", &weather.HTTPStatusError{StatusCode: 401}, exitInvalidAPIKey},
+		{"missing API key", fmt.Errorf("%w for OpenWeatherMap", weather.ErrMissingAPIKey), exitInvalidAPIKey},
+		{"unknown location", &weather.HTTPStatusError{StatusCode: 404}, exitLocationNotFound},
+		{"rate limited", &weather.HTTPStatusError{StatusCode: 429}, exitRateLimited},
+		{"unreachable", network, exitNetwork},
+		{"server error", &weather.HTTPStatusError{StatusCode: 500}, exitProviderError},
+		{"invalid response", &weather.DecodeError{Err: errors.New("unexpected end of JSON input")}, exitProviderError},
+		{"other", errors.New("no forecast data returned"), exitError},
+		// The most actionable cause wins when several providers failed
+		{
+			"chain",
+			errors.Join(fmt.Errorf("NWS: %w", network), fmt.Errorf("OpenWeatherMap: %w", &weather.HTTPStatusError{StatusCode: 401})),
+			exitInvalidAPIKey,
+		},
+		{
+			"chain of network and provider errors",
+			errors.Join(fmt.Errorf("NWS: %w", &weather.HTTPStatusError{StatusCode: 503}), fmt.Errorf("OpenMeteo: %w", network)),
+			exitNetwork,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := exitCode(tt.err); got != tt.want {
+				t.Errorf("got exit code %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatThousands(t *testing.T) {
+	tests := []struct {
+		n    int
+		want string
+	}{
+		{0, "0"},
+		{999, "999"},
+		{1000, "1,000"},
+		{116250, "116,250"},
+		{8804190, "8,804,190"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := formatThousands(tt.n); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}